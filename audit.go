@@ -0,0 +1,108 @@
+package pool
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// AuditEntry 是审计日志中的一条记录
+type AuditEntry struct {
+	Time      time.Time
+	EventType PoolEventType
+	ObjID     uintptr // 对象是指针等引用类型时为它的地址，否则为0
+	Goroutine int64   // 产生事件的goroutine
+	Error     error
+	RequestID string // 通过GetWithRequestID借出的对象的请求ID，只在为它创建对象、借出和放回时记录
+}
+
+// AuditLog 在内存中保存最近的事件，超出容量后覆盖最旧的记录
+type AuditLog struct {
+	mu      sync.Mutex
+	entries []AuditEntry
+	next    int // 下一条记录的位置
+	full    bool
+}
+
+func NewAuditLog(size int) *AuditLog {
+	if size <= 0 {
+		size = defaultEventBufferSize
+	}
+	return &AuditLog{entries: make([]AuditEntry, size)}
+}
+
+// SetAuditLog 设置审计日志，为nil时不再记录
+func (p *Pool) SetAuditLog(log *AuditLog) {
+	p.mu.Lock()
+	p.audit = log
+	p.mu.Unlock()
+}
+
+func (l *AuditLog) record(t time.Time, typ PoolEventType, obj interface{}, err error, reqID string) {
+	e := AuditEntry{Time: t, EventType: typ, ObjID: objID(obj), Goroutine: goroutineID(), Error: err, RequestID: reqID}
+	l.mu.Lock()
+	l.entries[l.next] = e
+	l.next++
+	if l.next == len(l.entries) {
+		l.next = 0
+		l.full = true
+	}
+	l.mu.Unlock()
+}
+
+// Entries 返回所有记录的快照，最旧的在前
+func (l *AuditLog) Entries() []AuditEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.full {
+		return append([]AuditEntry(nil), l.entries[:l.next]...)
+	}
+	return append(append([]AuditEntry(nil), l.entries[l.next:]...), l.entries[:l.next]...)
+}
+
+type auditJSON struct {
+	Time      time.Time `json:"time"`
+	EventType string    `json:"event"`
+	ObjID     uintptr   `json:"obj_id"`
+	Goroutine int64     `json:"goroutine"`
+	Error     string    `json:"error,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+}
+
+// WriteTo 把所有记录以JSON Lines格式写入w
+func (l *AuditLog) WriteTo(w io.Writer) (int64, error) {
+	cw := &countWriter{w: w}
+	enc := json.NewEncoder(cw)
+	for _, e := range l.Entries() {
+		j := auditJSON{Time: e.Time, EventType: e.EventType.String(), ObjID: e.ObjID, Goroutine: e.Goroutine, RequestID: e.RequestID}
+		if e.Error != nil {
+			j.Error = e.Error.Error()
+		}
+		if err := enc.Encode(j); err != nil {
+			return cw.n, err
+		}
+	}
+	return cw.n, nil
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countWriter) Write(b []byte) (int, error) {
+	n, err := w.w.Write(b)
+	w.n += int64(n)
+	return n, err
+}
+
+func objID(obj interface{}) uintptr {
+	v := reflect.ValueOf(obj)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Chan, reflect.Func, reflect.UnsafePointer, reflect.Slice:
+		return v.Pointer()
+	}
+	return 0
+}
@@ -0,0 +1,67 @@
+package pool
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPoolAuditLog(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 1)
+	log := NewAuditLog(10)
+	p.SetAuditLog(log)
+
+	o1, _ := p.Get()
+	o2, _ := p.Get()
+	p.Put(o1)
+	p.Put(o2) // 超出MaxIdle，o1被移除
+	p.Close()
+
+	var types []PoolEventType
+	for _, e := range log.Entries() {
+		types = append(types, e.EventType)
+	}
+	want := []PoolEventType{
+		EventCreated, EventBorrowed,
+		EventCreated, EventBorrowed,
+		EventReturned,
+		EventReturned, EventEvicted, EventDestroyed,
+		EventDestroyed,
+	}
+	if !reflect.DeepEqual(types, want) {
+		t.Errorf("events=%v, want %v", types, want)
+	}
+	if id := log.Entries()[0].ObjID; id != reflect.ValueOf(o1).Pointer() {
+		t.Errorf("ObjID=%x, want %p", id, o1)
+	}
+
+	var buf bytes.Buffer
+	if _, err := log.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("lines=%d, want %d", len(lines), len(want))
+	}
+	var e map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &e); err != nil {
+		t.Fatal(err)
+	}
+	if e["event"] != "created" {
+		t.Errorf("event=%v, want created", e["event"])
+	}
+
+	// 超出容量时覆盖最旧的记录
+	small := NewAuditLog(2)
+	p2 := NewPool(d.dial, 1)
+	p2.SetAuditLog(small)
+	o, _ := p2.Get()
+	p2.Put(o)
+	p2.Close()
+	if entries := small.Entries(); len(entries) != 2 || entries[1].EventType != EventDestroyed {
+		t.Errorf("entries=%v", entries)
+	}
+}
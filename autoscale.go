@@ -0,0 +1,32 @@
+package pool
+
+import "log/slog"
+
+// autoScale 根据等待者数量调整MaxActive
+func (p *Pool) autoScale() {
+	p.mu.Lock()
+	step := p.AutoScaleStep
+	if step <= 0 {
+		step = 1
+	}
+	min := p.AutoScaleMin
+	if min < 1 {
+		min = 1 // MaxActive为0表示不限制，不能缩到0
+	}
+	old := p.MaxActive
+	if len(p.waiters) > 0 && p.MaxActive < p.AutoScaleMax {
+		p.MaxActive += step
+		if p.MaxActive > p.AutoScaleMax {
+			p.MaxActive = p.AutoScaleMax
+		}
+		p.broadcast()
+	} else if len(p.waiters) == 0 && p.active < p.MaxActive-step && p.MaxActive-step >= min {
+		p.MaxActive -= step
+	}
+	if n := p.MaxActive; n != old {
+		p.log(slog.LevelInfo, "pool: autoscale", "from", old, "to", n)
+	}
+	logs := p.takeLogs()
+	p.mu.Unlock()
+	emitLogs(logs)
+}
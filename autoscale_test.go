@@ -0,0 +1,84 @@
+package pool
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func maxActive(p *Pool) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.MaxActive
+}
+
+func TestPoolAutoScale(t *testing.T) {
+	d := &poolDialer{t: t}
+	logger := &lockedBuffer{}
+	p := &Pool{
+		New:               d.dial,
+		DropCallback:      d.drop,
+		MaxIdle:           0, // Put后对象直接丢弃，active才会下降
+		MaxActive:         1,
+		Wait:              true,
+		AutoScale:         true,
+		AutoScaleMin:      1,
+		AutoScaleMax:      2,
+		AutoScaleStep:     1,
+		AutoScaleInterval: 10 * time.Millisecond,
+		Logger:            logger, // 没有调用SetLogger时以文本格式输出
+	}
+
+	o1, _ := p.Get()
+	got := make(chan interface{})
+	go func() {
+		o, _ := p.Get()
+		got <- o
+	}()
+
+	var o2 interface{}
+	select {
+	case o2 = <-got:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for scale up")
+	}
+	if n := maxActive(p); n != 2 {
+		t.Errorf("MaxActive=%d, want 2", n)
+	}
+
+	p.Put(o1)
+	p.Put(o2)
+	deadline := time.Now().Add(2 * time.Second)
+	for maxActive(p) != 1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := maxActive(p); n != 1 {
+		t.Errorf("MaxActive=%d, want 1", n)
+	}
+	p.Close()
+
+	for _, want := range []string{"from=1 to=2", "from=2 to=1"} {
+		if !strings.Contains(logger.String(), want) {
+			t.Errorf("log %q does not contain %q", logger.String(), want)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package pool
+
+import (
+	"context"
+	"sync"
+)
+
+// BatchDo 并发地对每个fn调用WithResource(ctx, fn)，最多同时运行BatchConcurrency个（为0时不限制），
+// 返回与fns一一对应的错误，获取对象失败时对应的是获取对象的错误。对象的获取与Get()相同，受MaxActive和Wait的限制
+func (p *Pool) BatchDo(ctx context.Context, fns []func(interface{}) error) []error {
+	p.mu.Lock()
+	n := p.BatchConcurrency
+	p.mu.Unlock()
+	if n <= 0 || n > len(fns) {
+		n = len(fns)
+	}
+
+	errs := make([]error, len(fns))
+	sem := make(chan struct{}, n)
+	var wg sync.WaitGroup
+	for i, fn := range fns {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, fn func(interface{}) error) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			errs[i] = p.WithResource(ctx, fn)
+		}(i, fn)
+	}
+	wg.Wait()
+	return errs
+}
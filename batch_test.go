@@ -0,0 +1,50 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPoolBatchDo(t *testing.T) {
+	p := &Pool{
+		MaxIdle:          2,
+		MaxActive:        2,
+		Wait:             true,
+		BatchConcurrency: 3,
+		New:              func() (interface{}, error) { return &conn{}, nil },
+	}
+	defer p.Close()
+
+	var running, maxRunning atomic.Int32
+	fnErr := errors.New("fn failed")
+	fn := func(err error) func(interface{}) error {
+		return func(interface{}) error {
+			n := running.Add(1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+			return err
+		}
+	}
+	errs := p.BatchDo(context.Background(), []func(interface{}) error{fn(nil), fn(fnErr), fn(nil), fn(nil)})
+	if len(errs) != 4 || errs[0] != nil || errs[1] != fnErr || errs[2] != nil || errs[3] != nil {
+		t.Errorf("BatchDo()=%v", errs)
+	}
+	if m := maxRunning.Load(); m != 2 { // 受MaxActive的限制
+		t.Errorf("max concurrent fns=%d, want 2", m)
+	}
+
+	p.Close()
+	errs = p.BatchDo(context.Background(), []func(interface{}) error{fn(nil)})
+	if !errors.Is(errs[0], ErrPoolClosed) {
+		t.Errorf("BatchDo() after close: %v, want ErrPoolClosed", errs[0])
+	}
+}
@@ -0,0 +1,134 @@
+package pool
+
+import (
+	"context"
+	"time"
+)
+
+// PoolConfig 是可以在运行时修改的配置
+type PoolConfig struct {
+	MaxIdle     int
+	MaxActive   int
+	IdleTimeout time.Duration
+	Wait        bool
+	WaitTimeout time.Duration
+}
+
+// Watch 启动一个goroutine，从updates中读取配置并应用到pool，updates被关闭或调用返回的函数后停止
+func (p *Pool) Watch(updates <-chan PoolConfig) context.CancelFunc {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		for {
+			select {
+			case c, ok := <-updates:
+				if !ok {
+					return
+				}
+				p.applyConfig(c)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return cancel
+}
+
+// applyConfig 应用新的配置，超出MaxIdle的空闲对象会从最旧的开始丢弃，
+// MaxActive变小时已有的对象不会被丢弃，只是不再创建新的对象
+func (p *Pool) applyConfig(c PoolConfig) {
+	p.mu.Lock()
+	p.MaxIdle = c.MaxIdle
+	p.MaxActive = c.MaxActive
+	p.IdleTimeout = c.IdleTimeout
+	p.Wait = c.Wait
+	p.WaitTimeout = c.WaitTimeout
+	p.applyLimits()
+}
+
+// SetMaxIdle 在运行时修改MaxIdle，超出的空闲对象会从最旧的开始丢弃
+func (p *Pool) SetMaxIdle(n int) {
+	p.mu.Lock()
+	p.MaxIdle = n
+	p.applyLimits()
+}
+
+// SetMaxActive 在运行时修改MaxActive，变小时已有的对象不会被丢弃，只是不再创建新的对象；
+// 设置了MaxIdlePercent时空闲对象的上限也会随之调整
+func (p *Pool) SetMaxActive(n int) {
+	p.mu.Lock()
+	p.MaxActive = n
+	p.applyLimits()
+}
+
+// applyLimits 丢弃超出MaxIdle的空闲对象并唤醒等待者，调用时需持有锁，返回时会释放锁
+func (p *Pool) applyLimits() {
+	var objs []interface{}
+	for p.idle.Len() > p.maxIdle() {
+		io := p.idle.Remove(p.idle.Back())
+		obj := io.Obj
+		p.retire(io)
+		p.evict(obj, "max idle")
+		p.recycle(io)
+		objs = append(objs, obj)
+	}
+	p.broadcast() // MaxActive变大或者Wait变为false时，等待者需要重新检查
+	p.dropObjs(objs...)
+}
+
+// SetNew 在运行时替换创建对象的函数（同时清除NewContext和NewWithEndpoint），之后创建的对象都使用fn，
+// 已有的对象不受影响，可以再调用Drain()丢弃旧的空闲对象
+func (p *Pool) SetNew(fn func() (interface{}, error)) {
+	p.mu.Lock()
+	p.New = fn
+	p.NewContext = nil
+	p.NewWithEndpoint = nil
+	p.mu.Unlock()
+}
+
+// FlushAndReload 原子地替换创建对象的函数（同时清除NewContext和NewWithEndpoint）并丢弃所有空闲对象，返回丢弃的数量。
+// 借出的对象不受影响，但和Reset一样，有记录的对象（开启TrackActive等选项时）不再计入active，放回时会被丢弃。
+// 避免先Drain()再SetNew()之间借出或放回旧的对象
+func (p *Pool) FlushAndReload(newDial func() (interface{}, error)) int {
+	p.mu.Lock()
+	p.New = newDial
+	p.NewContext = nil
+	p.NewWithEndpoint = nil
+	objs := p.takeIdle()
+	for _, obj := range objs {
+		p.release()
+		p.evict(obj, "reloaded")
+	}
+	p.bumpGeneration()
+	p.broadcast()
+	p.dropObjs(objs...)
+	return len(objs)
+}
+
+// SetDropCallback 在运行时替换丢弃对象的回调，之后丢弃的对象都调用fn
+func (p *Pool) SetDropCallback(fn func(interface{})) {
+	p.mu.Lock()
+	p.DropCallback = fn
+	p.mu.Unlock()
+}
+
+// SetTestOnBorrow 在运行时替换借出前检查对象的函数，为nil时不再检查
+func (p *Pool) SetTestOnBorrow(fn func(interface{}) error) {
+	p.mu.Lock()
+	p.TestOnBorrow = fn
+	p.mu.Unlock()
+}
+
+// SetIdleTimeout 在运行时修改IdleTimeout，只影响之后放回的对象，已有空闲对象的过期时间不变
+func (p *Pool) SetIdleTimeout(d time.Duration) {
+	p.mu.Lock()
+	p.IdleTimeout = d
+	p.mu.Unlock()
+}
+
+// SetVersion 修改ConnectionVersion，之后创建的对象记录版本v，版本小于v的空闲对象在被Get()取到时丢弃，
+// 不会一次性丢弃所有空闲对象。借出的旧对象放回后同样会在下次被取到时丢弃
+func (p *Pool) SetVersion(v uint64) {
+	p.mu.Lock()
+	p.ConnectionVersion = v
+	p.mu.Unlock()
+}
@@ -0,0 +1,199 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPoolWatch(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 3)
+	p.MaxActive = 3
+	defer p.Close()
+
+	var objs []interface{}
+	for i := 0; i < 3; i++ {
+		o, _ := p.Get()
+		objs = append(objs, o)
+	}
+	for _, o := range objs {
+		p.Put(o)
+	}
+
+	updates := make(chan PoolConfig)
+	stop := p.Watch(updates)
+	defer stop()
+	updates <- PoolConfig{MaxIdle: 1, MaxActive: 1, Wait: true, WaitTimeout: 10 * time.Millisecond}
+	waitActive(t, p, 1)
+	if n := p.IdleCount(); n != 1 {
+		t.Errorf("IdleCount()=%d, want 1", n)
+	}
+
+	o, _ := p.Get()
+	if _, err := p.Get(); !errors.Is(err, ErrWaitTimeout) {
+		t.Errorf("err=%v, want %v", err, ErrWaitTimeout)
+	}
+	p.Put(o)
+}
+
+func TestPoolSetNew(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := &Pool{
+		MaxIdle:    2,
+		NewContext: func(context.Context) (interface{}, error) { return d.dial() },
+	}
+	p.DropCallback = d.drop
+	defer p.Close()
+
+	o1, _ := p.Get()
+	p.Put(o1)
+
+	d2 := &poolDialer{t: t}
+	p.SetNew(d2.dial)
+	if o, _ := p.Get(); o != o1 { // 空闲对象不受影响
+		t.Errorf("Get()=%v, want %v", o, o1)
+	}
+	o2, err := p.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d2.dialed != 1 || d.dialed != 1 {
+		t.Errorf("dialed old=%d new=%d, want 1 and 1", d.dialed, d2.dialed)
+	}
+	p.Put(o1)
+	p.Put(o2)
+}
+
+func TestPoolFlushAndReload(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+	p.TrackActive = true
+	defer p.Close()
+
+	o1, _ := p.Get()
+	o2, _ := p.Get()
+	p.Put(o2)
+
+	d2 := &poolDialer{t: t}
+	if n := p.FlushAndReload(d2.dial); n != 1 {
+		t.Errorf("FlushAndReload()=%d, want 1", n)
+	}
+	if d.open != 1 || p.ActiveCount() != 0 { // o1仍然借出，但不再计入active
+		t.Errorf("open=%d active=%d, want 1 and 0", d.open, p.ActiveCount())
+	}
+	o3, err := p.Get()
+	if err != nil || o3 == o1 || o3 == o2 {
+		t.Fatalf("Get()=%v, %v, want a new object", o3, err)
+	}
+	if d2.dialed != 1 {
+		t.Errorf("new dialed=%d, want 1", d2.dialed)
+	}
+	p.Put(o1) // 旧对象放回时被丢弃
+	if d.open != 0 || p.ActiveCount() != 1 {
+		t.Errorf("open=%d active=%d, want 0 and 1", d.open, p.ActiveCount())
+	}
+	p.Put(o3)
+	if n := p.IdleCount(); n != 1 {
+		t.Errorf("IdleCount()=%d, want 1", n)
+	}
+}
+
+func TestPoolSetLimits(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 3)
+	p.DropCallback = d.drop
+	p.MaxActive = 3
+	p.Wait = true
+	defer p.Close()
+
+	var objs []interface{}
+	for i := 0; i < 3; i++ {
+		o, _ := p.Get()
+		objs = append(objs, o)
+	}
+	got := make(chan interface{})
+	go func() {
+		o, _ := p.Get()
+		got <- o
+	}()
+	waitWaiters(t, p, 1)
+	p.SetMaxActive(4) // 唤醒等待者创建新对象
+	select {
+	case o := <-got:
+		objs = append(objs, o)
+	case <-time.After(time.Second):
+		t.Fatal("waiter was not woken after SetMaxActive")
+	}
+
+	for _, o := range objs[:3] {
+		p.Put(o)
+	}
+	p.SetMaxIdle(1)
+	d.check("after SetMaxIdle", p, 4, 2)
+	p.Put(objs[3])
+	d.check("after Put", p, 4, 1)
+}
+
+func TestPoolSetCallbacks(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 1)
+	defer p.Close()
+
+	done := make(chan struct{})
+	go func() { // 和Get、Put同时修改，用-race检查
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			p.SetDropCallback(d.drop)
+			p.SetTestOnBorrow(func(interface{}) error { return nil })
+			p.SetIdleTimeout(time.Minute)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		o1, _ := p.Get()
+		o2, _ := p.Get()
+		p.Put(o1)
+		p.Put(o2)
+	}
+	<-done
+
+	var dropped []interface{}
+	p.SetDropCallback(func(obj interface{}) { dropped = append(dropped, obj) })
+	p.SetTestOnBorrow(func(interface{}) error { return errors.New("failed") })
+	idle := p.ExportIdle() // 找到空闲对象后再放回去
+	p.ImportIdle(idle)
+	o, _ := p.Get() // 空闲对象没有通过检查，被新的DropCallback丢弃
+	if len(dropped) != 1 || dropped[0] != idle[0] || o == idle[0] {
+		t.Errorf("dropped=%v, want %v", dropped, idle)
+	}
+	p.Put(o)
+}
+
+func TestPoolSetVersion(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+	defer p.Close()
+
+	o1, _ := p.Get()
+	o2, _ := p.Get()
+	p.Put(o1)
+	p.SetVersion(1)
+	p.Put(o2) // 在SetVersion之前创建，放回后仍然是旧版本
+	if n := p.IdleCount(); n != 2 {
+		t.Errorf("IdleCount()=%d, want 2", n)
+	}
+
+	o3, _ := p.Get()
+	if o3 == o1 || o3 == o2 {
+		t.Errorf("Get()=%v, want a new object", o3)
+	}
+	d.check("after SetVersion", p, 3, 1)
+	p.Put(o3)
+	if o, _ := p.Get(); o != o3 {
+		t.Errorf("Get()=%v, want %v", o, o3)
+	}
+	p.Put(o3)
+}
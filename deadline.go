@@ -0,0 +1,19 @@
+package pool
+
+// reclaimBorrowed 回收借出超过BorrowDeadline的对象并返回它们，调用时需持有锁
+func (p *Pool) reclaimBorrowed() []interface{} {
+	if !p.TrackActive || p.BorrowDeadline <= 0 {
+		return nil
+	}
+	var leaked []interface{}
+	now := nowFunc()
+	for obj, io := range p.borrowed {
+		if now.Sub(io.borrowedAt) >= p.BorrowDeadline {
+			if _, ok := p.untrack(obj); ok {
+				p.retire(io)
+			}
+			leaked = append(leaked, obj)
+		}
+	}
+	return leaked
+}
@@ -0,0 +1,36 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPoolBorrowDeadline(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+	p.TrackActive = true
+	p.JanitorInterval = 10 * time.Millisecond
+	p.BorrowDeadline = 20 * time.Millisecond
+	exceeded := make(chan interface{}, 1)
+	p.OnBorrowDeadlineExceeded = func(o interface{}) {
+		exceeded <- o
+	}
+
+	o, _ := p.Get()
+	select {
+	case got := <-exceeded:
+		if got != o {
+			t.Errorf("got %p, want %p", got, o)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for borrow deadline")
+	}
+	if active := p.ActiveCount(); active != 0 {
+		t.Errorf("active=%d, want 0", active)
+	}
+
+	p.Put(o) // 已经被回收的对象会被直接丢弃
+	p.Close()
+	d.check("after close", p, 1, 0)
+}
@@ -0,0 +1,65 @@
+package pool
+
+import "log/slog"
+
+// degradeAlpha 是错误率指数加权移动平均中最近一次janitor统计的权重
+const degradeAlpha = 0.3
+
+// checkDegradation 由janitor调用，根据上次调用以来创建对象和TestOnBorrow的错误率调整降级时的MaxActive，调用时需持有锁
+func (p *Pool) checkDegradation() {
+	if p.DegradationThreshold <= 0 || p.MaxActive <= 0 {
+		p.degradeMax = 0
+		return
+	}
+	dials, hits, errs := p.stats.misses.Load(), p.stats.hits.Load(), p.stats.errors.Load()
+	attempts := dials - p.lastDials + hits - p.lastHits
+	failures := errs - p.lastErrors + p.healthFailures
+	p.lastDials, p.lastHits, p.lastErrors, p.healthFailures = dials, hits, errs, 0
+	if attempts <= 0 && failures <= 0 {
+		return // 没有新的请求，保持原来的状态
+	}
+	rate := 1.0
+	if attempts > failures {
+		rate = float64(failures) / float64(attempts)
+	}
+	p.errRate = degradeAlpha*rate + (1-degradeAlpha)*p.errRate
+
+	recovery := p.RecoveryThreshold
+	if recovery <= 0 {
+		recovery = p.DegradationThreshold / 2
+	}
+	switch {
+	case p.errRate >= p.DegradationThreshold:
+		factor := p.DegradationFactor
+		if factor <= 0 || factor >= 1 {
+			factor = 0.5
+		}
+		max := p.maxActive()
+		next := int(float64(max) * factor)
+		if next < 1 {
+			next = 1
+		}
+		if next < max {
+			p.degradeMax = next
+			p.log(slog.LevelWarn, "pool: degraded", "error_rate", p.errRate, "max_active", next)
+		}
+	case p.degradeMax > 0 && p.errRate < recovery: // 每次恢复MaxActive的十分之一
+		step := p.MaxActive / 10
+		if step < 1 {
+			step = 1
+		}
+		p.degradeMax += step
+		if p.degradeMax >= p.MaxActive {
+			p.degradeMax = 0
+			p.log(slog.LevelInfo, "pool: recovered", "error_rate", p.errRate)
+		}
+		p.broadcast() // 上限变大，等待者需要重新检查
+	}
+}
+
+// IsDegraded 返回pool是否因为错误率超过DegradationThreshold而降低了MaxActive
+func (p *Pool) IsDegraded() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.degradeMax > 0
+}
@@ -0,0 +1,53 @@
+package pool
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPoolDegradation(t *testing.T) {
+	fail := true
+	p := &Pool{
+		MaxIdle:              10,
+		MaxActive:            10,
+		DegradationThreshold: 0.5,
+		New: func() (interface{}, error) {
+			if fail {
+				return nil, errors.New("dial failed")
+			}
+			return &conn{}, nil
+		},
+	}
+	defer p.Close()
+	sweep := func(gets int) int {
+		for i := 0; i < gets; i++ {
+			if o, err := p.Get(); err == nil {
+				p.PutErr(o, errors.New("not reused")) // 每次都创建新对象
+			}
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.checkDegradation()
+		return p.maxActive()
+	}
+
+	if max := sweep(10); max != 10 { // 移动平均还没有超过阈值
+		t.Errorf("maxActive=%d after one failing sweep, want 10", max)
+	}
+	if max := sweep(10); max != 5 || !p.IsDegraded() {
+		t.Errorf("maxActive=%d, degraded=%t, want 5 and true", max, p.IsDegraded())
+	}
+
+	fail = false
+	last := 5
+	for i := 0; i < 20 && p.IsDegraded(); i++ {
+		max := sweep(10)
+		if max < last {
+			t.Fatalf("maxActive decreased from %d to %d while recovering", last, max)
+		}
+		last = max
+	}
+	if p.IsDegraded() || last != 10 {
+		t.Errorf("degraded=%t, maxActive=%d, want recovered to 10", p.IsDegraded(), last)
+	}
+}
@@ -0,0 +1,111 @@
+package pool
+
+import (
+	"context"
+	"time"
+)
+
+// dialBucket 是限制创建对象速率的令牌桶
+type dialBucket struct {
+	tokens float64
+	last   time.Time // 上次计算tokens的时间，为零值时桶是满的
+}
+
+// takeDialToken 取出一个创建对象的令牌，没有令牌时返回还需要等待多久，没有设置MaxDialRate时总是返回0。调用时需持有锁
+func (p *Pool) takeDialToken() time.Duration {
+	if p.MaxDialRate <= 0 {
+		return 0
+	}
+	burst := float64(p.MaxDialBurst)
+	if burst < 1 {
+		burst = 1
+	}
+	b, now := &p.dialBucket, nowFunc()
+	if b.last.IsZero() {
+		b.tokens = burst
+	} else if b.tokens += now.Sub(b.last).Seconds() * p.MaxDialRate; b.tokens > burst {
+		b.tokens = burst
+	}
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / p.MaxDialRate * float64(time.Second))
+}
+
+// returnDialToken 放回takeDialToken取出的令牌，调用时需持有锁
+func (p *Pool) returnDialToken() {
+	if p.MaxDialRate > 0 {
+		p.dialBucket.tokens++
+	}
+}
+
+// maxDials 返回同时创建对象的上限，为0时不限制，调用时需持有锁
+func (p *Pool) maxDials() int {
+	n := p.MaxConcurrentDials
+	if p.MaxDialConcurrency > n {
+		n = p.MaxDialConcurrency
+	}
+	if n == 0 && p.SerializeDial {
+		n = 1
+	}
+	return n
+}
+
+// limitDial 返回排队创建对象的dial，同时创建对象的数量不超过maxDials()，上限改变时重新创建dialSem，
+// 已经拿到旧的dialSem的创建仍然在旧的上面排队和释放。
+// 排队时ctx被取消会返回ctx.Err()，设置了WaitTimeout时最多排队WaitTimeout，超时返回包装了context.DeadlineExceeded的ErrWaitTimeout。
+// 需要在detachDial里面包装，这样调用者因为DialTimeout或ctx放弃之后，名额仍然占用到dial真正返回，排队的时间也计入DialTimeout。
+// 调用时需持有锁
+func (p *Pool) limitDial(dial func(context.Context) (interface{}, error)) func(context.Context) (interface{}, error) {
+	if n := p.maxDials(); cap(p.dialSem) != n {
+		p.dialSem = make(chan struct{}, n)
+	}
+	sem, waitTimeout, name := p.dialSem, p.WaitTimeout, p.Name
+	return func(ctx context.Context) (interface{}, error) {
+		select {
+		case sem <- struct{}{}:
+		default:
+			var timeout <-chan time.Time
+			if waitTimeout > 0 {
+				t := time.NewTimer(waitTimeout)
+				defer t.Stop()
+				timeout = t.C
+			}
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-timeout:
+				return nil, &PoolError{Code: ErrCodeWaitTimeout, Pool: name, Msg: ErrWaitTimeout.Msg, Err: context.DeadlineExceeded}
+			}
+		}
+		defer func() { <-sem }()
+		if err := ctx.Err(); err != nil { // 排队期间调用者已经放弃
+			return nil, err
+		}
+		return dial(ctx)
+	}
+}
+
+// countDial 返回记录正在创建的对象数量的dial，见CurrentDials
+func (p *Pool) countDial(dial func(context.Context) (interface{}, error)) func(context.Context) (interface{}, error) {
+	return func(ctx context.Context) (interface{}, error) {
+		p.dialing.Add(1)
+		defer p.dialing.Add(-1)
+		return dial(ctx)
+	}
+}
+
+// CurrentDials 返回正在调用New（或NewContext、NewWithEndpoint）的数量，不包括排队等待MaxConcurrentDials的
+func (p *Pool) CurrentDials() int {
+	return int(p.dialing.Load())
+}
+
+// after 返回一个d之后会被关闭的channel
+func after(d time.Duration) <-chan struct{} {
+	ch := make(chan struct{})
+	time.AfterFunc(d, func() { close(ch) })
+	return ch
+}
@@ -0,0 +1,219 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPoolMaxDialRate(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 4)
+	p.MaxDialRate = 1
+	p.MaxDialBurst = 2
+	defer p.Close()
+
+	now := time.Now()
+	nowFunc = func() time.Time {
+		return now
+	}
+	defer func() {
+		nowFunc = time.Now
+	}()
+
+	o1, _ := p.Get()
+	o2, _ := p.Get()
+	if _, err := p.Get(); !errors.Is(err, ErrPoolExhausted) {
+		t.Errorf("Get()=%v, want ErrPoolExhausted", err)
+	}
+	p.Put(o1) // 空闲对象不受限制
+	if o, err := p.Get(); o != o1 || err != nil {
+		t.Errorf("Get()=%v, %v, want %v", o, err, o1)
+	}
+	now = now.Add(time.Second)
+	if _, err := p.Get(); err != nil {
+		t.Errorf("Get() after 1s: %v", err)
+	}
+	d.check("dial rate", p, 3, 3)
+	p.Put(o2)
+}
+
+func TestPoolMaxDialRateWait(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.MaxDialRate = 50
+	p.Wait = true
+	defer p.Close()
+
+	start := time.Now()
+	o1, _ := p.Get()
+	o2, err := p.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+		t.Errorf("elapsed %v, want >= 10ms", elapsed)
+	}
+	p.Put(o1)
+	p.Put(o2)
+}
+
+func TestPoolSerializeDial(t *testing.T) {
+	dialing := make(chan struct{}, 2)
+	unblock := make(chan struct{})
+	p := &Pool{
+		MaxIdle:       2,
+		SerializeDial: true,
+		New: func() (interface{}, error) {
+			dialing <- struct{}{}
+			<-unblock
+			return &conn{}, nil
+		},
+	}
+	defer p.Close()
+
+	got := make(chan interface{}, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			o, _ := p.Get()
+			got <- o
+		}()
+	}
+	<-dialing
+	select {
+	case <-dialing:
+		t.Fatal("second dial started while the first one was in progress")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := p.GetContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("GetContext()=%v, want DeadlineExceeded", err)
+	}
+
+	close(unblock)
+	<-dialing
+	p.Put(<-got)
+	p.Put(<-got)
+}
+
+func TestPoolCurrentDials(t *testing.T) {
+	dialing := make(chan struct{}, 1)
+	unblock := make(chan struct{})
+	p := &Pool{
+		MaxIdle:            2,
+		MaxConcurrentDials: 1,
+		WaitTimeout:        10 * time.Millisecond,
+		New: func() (interface{}, error) {
+			dialing <- struct{}{}
+			<-unblock
+			return &conn{}, nil
+		},
+	}
+	defer p.Close()
+
+	got := make(chan interface{})
+	go func() {
+		o, _ := p.Get()
+		got <- o
+	}()
+	<-dialing
+	if n := p.CurrentDials(); n != 1 {
+		t.Errorf("CurrentDials()=%d, want 1", n)
+	}
+	if _, err := p.Get(); !errors.Is(err, ErrWaitTimeout) || !errors.Is(err, context.DeadlineExceeded) { // 排队超过WaitTimeout
+		t.Errorf("Get()=%v, want %v", err, ErrWaitTimeout)
+	}
+	close(unblock)
+	p.Put(<-got)
+	if n := p.CurrentDials(); n != 0 {
+		t.Errorf("CurrentDials()=%d after dial, want 0", n)
+	}
+}
+
+func TestPoolMaxConcurrentDialsChange(t *testing.T) {
+	dialing := make(chan struct{}, 2)
+	unblock := make(chan struct{})
+	p := &Pool{
+		MaxIdle:            2,
+		MaxDialConcurrency: 1,
+		WaitTimeout:        10 * time.Millisecond,
+		New: func() (interface{}, error) {
+			dialing <- struct{}{}
+			<-unblock
+			return &conn{}, nil
+		},
+	}
+	defer p.Close()
+
+	got := make(chan interface{}, 2)
+	go func() {
+		o, _ := p.Get()
+		got <- o
+	}()
+	<-dialing
+	if _, err := p.Get(); !errors.Is(err, ErrWaitTimeout) {
+		t.Errorf("Get()=%v, want %v", err, ErrWaitTimeout)
+	}
+
+	p.WithLock(func() { p.MaxConcurrentDials = 2 }) // 之后的创建使用新的上限
+	go func() {
+		o, _ := p.Get()
+		got <- o
+	}()
+	select {
+	case <-dialing:
+	case <-time.After(time.Second):
+		t.Fatal("dial did not start after raising MaxConcurrentDials")
+	}
+	close(unblock)
+	p.Put(<-got)
+	p.Put(<-got)
+}
+
+func TestPoolSerializeDialTimeout(t *testing.T) {
+	var mu sync.Mutex
+	cur, max := 0, 0
+	unblock := make(chan struct{})
+	p := &Pool{
+		MaxIdle:       5,
+		SerializeDial: true,
+		DialTimeout:   20 * time.Millisecond,
+		New: func() (interface{}, error) {
+			mu.Lock()
+			if cur++; cur > max {
+				max = cur
+			}
+			mu.Unlock()
+			<-unblock
+			mu.Lock()
+			cur--
+			mu.Unlock()
+			return &conn{}, nil
+		},
+	}
+	defer p.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := p.Get(); !errors.Is(err, ErrDialTimeout) {
+				t.Errorf("Get()=%v, want ErrDialTimeout", err)
+			}
+		}()
+	}
+	wg.Wait()
+	time.Sleep(20 * time.Millisecond) // 放弃的创建仍然占用名额，不会开始新的New
+	close(unblock)
+	time.Sleep(20 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if max != 1 {
+		t.Errorf("%d concurrent New() calls, want 1", max)
+	}
+}
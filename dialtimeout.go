@@ -0,0 +1,49 @@
+package pool
+
+import "context"
+
+type dialResult struct {
+	obj interface{}
+	err error
+}
+
+// withDialTimeout 返回最多等待DialTimeout的dial，dial需要已经由detachDial包装过，超时后不会再等待它返回。
+// 调用时需持有锁
+func (p *Pool) withDialTimeout(dial func(context.Context) (interface{}, error)) func(context.Context) (interface{}, error) {
+	timeout, errTimeout := p.DialTimeout, p.err(ErrDialTimeout)
+	return func(parent context.Context) (interface{}, error) {
+		ctx, cancel := context.WithTimeout(parent, timeout)
+		defer cancel()
+		obj, err := dial(ctx)
+		if err != nil && ctx.Err() != nil && parent.Err() == nil {
+			return nil, errTimeout
+		}
+		return obj, err
+	}
+}
+
+// detachDial 返回在另一个goroutine中调用dial的函数，ctx被取消或超时后不再等待，直接返回ctx.Err()并释放active，
+// 之后创建成功的对象会被丢弃（调用drop）。ctx不能被取消时直接调用dial
+func detachDial(dial func(context.Context) (interface{}, error), drop func(interface{})) func(context.Context) (interface{}, error) {
+	return func(ctx context.Context) (interface{}, error) {
+		if ctx.Done() == nil {
+			return dial(ctx)
+		}
+		ch := make(chan dialResult, 1)
+		go func() {
+			obj, err := dial(ctx)
+			ch <- dialResult{obj, err}
+		}()
+		select {
+		case r := <-ch:
+			return r.obj, r.err
+		case <-ctx.Done():
+			go func() { // 不再等待，创建成功的对象需要丢弃
+				if r := <-ch; r.err == nil && drop != nil {
+					drop(r.obj)
+				}
+			}()
+			return nil, ctx.Err()
+		}
+	}
+}
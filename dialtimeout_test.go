@@ -0,0 +1,95 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPoolDialTimeout(t *testing.T) {
+	unblock := make(chan struct{})
+	dropped := make(chan interface{}, 1)
+	p := &Pool{
+		MaxIdle:     1,
+		DialTimeout: 20 * time.Millisecond,
+		New: func() (interface{}, error) {
+			<-unblock
+			return &conn{}, nil
+		},
+		DropCallback: func(obj interface{}) { dropped <- obj },
+	}
+	defer p.Close()
+
+	if _, err := p.Get(); !errors.Is(err, ErrDialTimeout) {
+		t.Errorf("Get()=%v, want ErrDialTimeout", err)
+	}
+	if n := p.ActiveCount(); n != 0 {
+		t.Errorf("ActiveCount()=%d, want 0", n)
+	}
+	close(unblock) // 超时之后创建的对象会被丢弃
+	select {
+	case <-dropped:
+	case <-time.After(time.Second):
+		t.Error("object created after timeout was not dropped")
+	}
+}
+
+func TestPoolDialTimeoutContext(t *testing.T) {
+	p := &Pool{
+		MaxIdle:     1,
+		DialTimeout: 20 * time.Millisecond,
+		NewContext: func(ctx context.Context) (interface{}, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+	defer p.Close()
+
+	if _, err := p.Get(); !errors.Is(err, ErrDialTimeout) {
+		t.Errorf("Get()=%v, want ErrDialTimeout", err)
+	}
+	// 调用者的ctx更早超时时返回ctx的错误
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	p.mu.Lock()
+	p.DialTimeout = time.Second
+	p.mu.Unlock()
+	start := time.Now()
+	if _, err := p.GetContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("GetContext()=%v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("GetContext() took %v", elapsed)
+	}
+}
+
+func TestPoolDialCanceled(t *testing.T) {
+	unblock := make(chan struct{})
+	dropped := make(chan interface{}, 1)
+	p := &Pool{
+		MaxIdle:   1,
+		MaxActive: 1,
+		NewContext: func(context.Context) (interface{}, error) { // 不响应ctx
+			<-unblock
+			return &conn{}, nil
+		},
+		DropCallback: func(obj interface{}) { dropped <- obj },
+	}
+	defer p.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := p.GetContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("GetContext()=%v, want DeadlineExceeded", err)
+	}
+	if n := p.ActiveCount(); n != 0 { // 不用等NewContext返回
+		t.Errorf("ActiveCount()=%d, want 0", n)
+	}
+	close(unblock)
+	select {
+	case <-dropped:
+	case <-time.After(time.Second):
+		t.Error("object created after cancellation was not dropped")
+	}
+}
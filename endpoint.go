@@ -0,0 +1,12 @@
+package pool
+
+import "context"
+
+// GetFromEndpoint 优先返回endpoint相同的空闲对象，没有时与Get()相同（新对象仍然按轮询选择endpoint）。
+// 需要设置Endpoints和NewWithEndpoint
+func (p *Pool) GetFromEndpoint(endpoint string) (interface{}, error) {
+	prefer := func(io ConnectionInfo) bool {
+		return io.Endpoint == endpoint
+	}
+	return p.get(context.Background(), getOptions{prefer: prefer})
+}
@@ -0,0 +1,61 @@
+package pool
+
+import (
+	"errors"
+	"testing"
+)
+
+type endpointConn struct {
+	endpoint string
+}
+
+func TestPoolEndpoints(t *testing.T) {
+	p := &Pool{
+		MaxIdle:   4,
+		Endpoints: []string{"a", "b", "c"},
+		NewWithEndpoint: func(endpoint string) (interface{}, error) {
+			return &endpointConn{endpoint}, nil
+		},
+	}
+	defer p.Close()
+
+	var objs []interface{}
+	for _, want := range []string{"a", "b", "c", "a"} {
+		o, _ := p.Get()
+		if e := o.(*endpointConn).endpoint; e != want {
+			t.Errorf("endpoint=%s, want %s", e, want)
+		}
+		objs = append(objs, o)
+	}
+	for _, o := range objs {
+		p.Put(o)
+	}
+
+	o, _ := p.GetFromEndpoint("b")
+	if o != objs[1] {
+		t.Errorf("GetFromEndpoint(b)=%v, want %v", o, objs[1])
+	}
+	for _, info := range p.IdleConnections() {
+		if info.Endpoint != info.Obj.(*endpointConn).endpoint {
+			t.Errorf("ConnectionInfo.Endpoint=%s, want %s", info.Endpoint, info.Obj.(*endpointConn).endpoint)
+		}
+	}
+	if o2, _ := p.GetFromEndpoint("b"); o2 != objs[3] { // 没有endpoint相同的对象，返回最新的
+		t.Errorf("GetFromEndpoint(b)=%v, want %v", o2, objs[3])
+	}
+}
+
+func TestPoolEndpointsConfig(t *testing.T) {
+	p := &Pool{NewWithEndpoint: func(string) (interface{}, error) { return 1, nil }}
+	if err := p.Validate(); !errors.Is(err, &PoolError{Code: ErrCodeInvalidConfig}) {
+		t.Errorf("Validate()=%v, want ErrCodeInvalidConfig", err)
+	}
+	if _, err := p.Get(); err != errNoEndpoints {
+		t.Errorf("Get()=%v, want %v", err, errNoEndpoints)
+	}
+	p.New = func() (interface{}, error) { return 1, nil }
+	p.Endpoints = []string{"a"}
+	if _, err := p.Get(); err != errNewFunc {
+		t.Errorf("Get()=%v, want %v", err, errNewFunc)
+	}
+}
@@ -0,0 +1,104 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+)
+
+// PoolErrorCode 表示错误的类型
+type PoolErrorCode int
+
+const (
+	ErrCodeClosed PoolErrorCode = iota + 1
+	ErrCodeExhausted
+	ErrCodePaused
+	ErrCodeBorrowLimitExceeded
+	ErrCodeInvalidConfig
+	ErrCodeDialTimeout
+	ErrCodeSerialization // SaveState、LoadState保存或恢复对象失败
+
+	// 下面几种是ErrCodeExhausted的细分，errors.Is(err, ErrPoolExhausted)对它们也返回true
+	ErrCodeTooManyWaiters // 等待的goroutine达到MaxWaiters
+	ErrCodeWaitTimeout    // 等待超过WaitTimeout，Err是context.DeadlineExceeded
+	ErrCodeGroupFull      // 达到PoolGroup的上限
+	ErrCodeNoIdle         // 只借出空闲对象时没有空闲对象
+)
+
+// exhausted 返回c是否表示没有可以借出的对象
+func (c PoolErrorCode) exhausted() bool {
+	switch c {
+	case ErrCodeExhausted, ErrCodeTooManyWaiters, ErrCodeWaitTimeout, ErrCodeGroupFull, ErrCodeNoIdle:
+		return true
+	}
+	return false
+}
+
+// PoolError 是pool返回的错误，errors.Is会比较Code，所以同类型的错误都可以和下面的变量比较。
+// ErrPoolExhausted是例外，它可以匹配所有表示没有可以借出的对象的错误（Code.exhausted()）
+type PoolError struct {
+	Code PoolErrorCode
+	Pool string // pool的名字
+	Msg  string
+	Err  error // 导致这个错误的原因，可以为nil
+}
+
+func (e *PoolError) Error() string {
+	msg := e.Msg
+	if e.Pool != "" {
+		msg = fmt.Sprintf("%s (pool %s)", msg, e.Pool)
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *PoolError) Unwrap() error {
+	return e.Err
+}
+
+func (e *PoolError) Is(target error) bool {
+	t, ok := target.(*PoolError)
+	return ok && (t.Code == e.Code || t.Code == ErrCodeExhausted && e.Code.exhausted())
+}
+
+var (
+	ErrPoolClosed    = &PoolError{Code: ErrCodeClosed, Msg: "pool closed"}
+	ErrPoolExhausted = &PoolError{Code: ErrCodeExhausted, Msg: "pool exhausted"}
+	ErrPoolPaused    = &PoolError{Code: ErrCodePaused, Msg: "pool paused"}
+
+	ErrBorrowLimitExceeded = &PoolError{Code: ErrCodeBorrowLimitExceeded, Msg: "pool borrow limit exceeded"}
+	ErrDialTimeout         = &PoolError{Code: ErrCodeDialTimeout, Msg: "pool dial timeout"}
+
+	ErrTooManyWaiters = &PoolError{Code: ErrCodeTooManyWaiters, Msg: "pool exhausted: too many waiters"}
+	ErrWaitTimeout    = &PoolError{Code: ErrCodeWaitTimeout, Msg: "pool exhausted: wait timeout"}
+	ErrGroupFull      = &PoolError{Code: ErrCodeGroupFull, Msg: "pool exhausted: group limit reached"}
+
+	errNoIdle      = &PoolError{Code: ErrCodeNoIdle, Msg: "pool: no idle object"}
+	errNewFunc     = &PoolError{Code: ErrCodeInvalidConfig, Msg: "pool: exactly one of New, NewContext and NewWithEndpoint must be set"}
+	errNoEndpoints = &PoolError{Code: ErrCodeInvalidConfig, Msg: "pool: Endpoints must not be empty when NewWithEndpoint is set"}
+
+	errMirrorNotPointer = &PoolError{Code: ErrCodeInvalidConfig, Msg: "pool: MirroredPool needs pointer, map or channel objects"}
+)
+
+// err 返回带有pool名字的错误，没有设置Name时直接返回e，调用时需持有锁
+func (p *Pool) err(e *PoolError) error {
+	if p.Name == "" {
+		return e
+	}
+	ne := *e
+	ne.Pool = p.Name
+	return &ne
+}
+
+// waitErr 返回等待失败时的错误：ctx本身没有结束时是WaitTimeout等pool设置的等待时间到了，
+// 返回包装了err的ErrWaitTimeout，否则直接返回err，调用时需持有锁
+func (p *Pool) waitErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return err
+	}
+	e := *ErrWaitTimeout
+	e.Pool = p.Name
+	e.Err = err
+	return &e
+}
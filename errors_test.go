@@ -0,0 +1,85 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPoolError(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 1)
+	p.Name = "db"
+	p.MaxActive = 1
+
+	o, _ := p.Get()
+	_, err := p.Get()
+	if !errors.Is(err, ErrPoolExhausted) {
+		t.Errorf("err=%v, want %v", err, ErrPoolExhausted)
+	}
+	if err.Error() != "pool exhausted (pool db)" {
+		t.Errorf("message=%q", err.Error())
+	}
+	var pe *PoolError
+	if !errors.As(err, &pe) || pe.Code != ErrCodeExhausted || pe.Pool != "db" {
+		t.Errorf("unexpected error %#v", err)
+	}
+	if errors.Is(err, ErrPoolClosed) {
+		t.Errorf("%v should not be %v", err, ErrPoolClosed)
+	}
+
+	p.Put(o)
+	p.Close()
+	if _, err := p.Get(); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("err=%v, want %v", err, ErrPoolClosed)
+	}
+}
+
+func TestPoolErrorExhaustedCodes(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 1)
+	p.MaxActive = 1
+	p.Wait = true
+	p.WaitTimeout = 10 * time.Millisecond
+	defer p.Close()
+
+	o, _ := p.Get()
+	_, err := p.Get()
+	var pe *PoolError
+	if !errors.As(err, &pe) || pe.Code != ErrCodeWaitTimeout {
+		t.Errorf("err=%#v, want Code ErrCodeWaitTimeout", err)
+	}
+	if !errors.Is(err, ErrPoolExhausted) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err=%v should be both %v and %v", err, ErrPoolExhausted, context.DeadlineExceeded)
+	}
+	if errors.Is(ErrPoolExhausted, ErrWaitTimeout) {
+		t.Errorf("%v should not be %v", ErrPoolExhausted, ErrWaitTimeout)
+	}
+
+	// 调用者的ctx超时不是WaitTimeout
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if _, err := p.GetContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("GetContext()=%v, want %v", err, context.DeadlineExceeded)
+	}
+
+	p.MaxWaiters = 1
+	got := make(chan error, 1)
+	p.WaitTimeout = time.Second
+	go func() {
+		o, err := p.Get()
+		if err == nil {
+			p.Put(o)
+		}
+		got <- err
+	}()
+	waitWaiters(t, p, 1)
+	if _, err := p.Get(); !errors.As(err, &pe) || pe.Code != ErrCodeTooManyWaiters || !errors.Is(err, ErrPoolExhausted) {
+		t.Errorf("err=%v, want %v", err, ErrTooManyWaiters)
+	}
+	p.Put(o)
+	if err := <-got; err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,118 @@
+package pool
+
+import (
+	"log/slog"
+	"time"
+)
+
+const defaultEventBufferSize = 100
+
+type PoolEventType int
+
+const (
+	EventCreated           PoolEventType = iota // 创建了新对象，创建失败时Err不为nil
+	EventDestroyed                              // 对象被丢弃
+	EventBorrowed                               // 对象被借出
+	EventReturned                               // 对象被放回空闲列表
+	EventEvicted                                // 空闲对象因为过期或超出MaxIdle被移除
+	EventExhausted                              // 对象数达到了MaxActive
+	EventHealthCheckFailed                      // TestOnBorrow或Ping失败
+)
+
+func (t PoolEventType) String() string {
+	switch t {
+	case EventCreated:
+		return "created"
+	case EventDestroyed:
+		return "destroyed"
+	case EventBorrowed:
+		return "borrowed"
+	case EventReturned:
+		return "returned"
+	case EventEvicted:
+		return "evicted"
+	case EventExhausted:
+		return "exhausted"
+	case EventHealthCheckFailed:
+		return "health check failed"
+	}
+	return "unknown"
+}
+
+type PoolEvent struct {
+	Type PoolEventType
+	Obj  interface{}
+	Err  error
+	Time time.Time
+}
+
+// SetEventBufferSize 设置Events()返回的channel的容量，需要在第一次调用Events()之前设置
+func (p *Pool) SetEventBufferSize(n int) {
+	p.mu.Lock()
+	p.eventBufferSize = n
+	p.mu.Unlock()
+}
+
+// Events 返回发布pool事件的channel，channel满了之后新的事件会被丢弃。
+// 第一次调用Events()之后才会发布事件
+func (p *Pool) Events() <-chan PoolEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.events == nil {
+		n := p.eventBufferSize
+		if n <= 0 {
+			n = defaultEventBufferSize
+		}
+		p.events = make(chan PoolEvent, n)
+	}
+	return p.events
+}
+
+// eventSink 是事件的接收者，需要在不持有锁时发布事件时，先在持有锁时通过sink()获取
+type eventSink struct {
+	ch     chan PoolEvent
+	audit  *AuditLog
+	logger *slog.Logger // 只用于创建对象的日志，创建对象的事件都是在锁外发布的
+	reqID  string       // 记录在审计日志中的请求ID
+}
+
+// sink 返回当前的事件接收者，调用时需持有锁
+func (p *Pool) sink() eventSink {
+	return eventSink{ch: p.events, audit: p.audit, logger: p.logWith()}
+}
+
+// requestSink 与sink相同，但审计日志会记录reqID，调用时需持有锁
+func (p *Pool) requestSink(reqID string) eventSink {
+	s := p.sink()
+	s.reqID = reqID
+	return s
+}
+
+// event 发布一个事件，调用时需持有锁
+func (p *Pool) event(t PoolEventType, obj interface{}, err error) {
+	publish(p.sink(), t, obj, err)
+}
+
+func publish(s eventSink, t PoolEventType, obj interface{}, err error) {
+	if s.logger != nil && t == EventCreated {
+		if err != nil {
+			s.logger.Warn("pool: create failed", "error", err)
+		} else {
+			s.logger.Debug("pool: object created")
+		}
+	}
+	if s.ch == nil && s.audit == nil {
+		return
+	}
+	now := nowFunc()
+	if s.audit != nil {
+		s.audit.record(now, t, obj, err, s.reqID)
+	}
+	if s.ch == nil {
+		return
+	}
+	select {
+	case s.ch <- PoolEvent{Type: t, Obj: obj, Err: err, Time: now}:
+	default:
+	}
+}
@@ -0,0 +1,63 @@
+package pool
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func eventTypes(ch <-chan PoolEvent) []PoolEventType {
+	var types []PoolEventType
+	for {
+		select {
+		case e := <-ch:
+			types = append(types, e.Type)
+		default:
+			return types
+		}
+	}
+}
+
+func TestPoolEvents(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 1)
+	p.DropCallback = d.drop
+	p.MaxActive = 1
+	events := p.Events()
+
+	o, _ := p.Get()
+	if _, err := p.Get(); err != ErrPoolExhausted {
+		t.Fatalf("err=%v, want %v", err, ErrPoolExhausted)
+	}
+	p.Put(o)
+
+	p.TestOnBorrow = func(interface{}) error { return errors.New("bad") }
+	o, _ = p.Get()
+	p.Close()
+	p.Put(o)
+
+	want := []PoolEventType{
+		EventCreated, EventBorrowed,
+		EventExhausted,
+		EventReturned,
+		EventHealthCheckFailed, EventDestroyed, EventCreated, EventBorrowed,
+		EventDestroyed,
+	}
+	if got := eventTypes(events); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("events=%v, want %v", got, want)
+	}
+}
+
+func TestPoolEventsBufferFull(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 1)
+	p.SetEventBufferSize(1)
+	events := p.Events()
+
+	o, _ := p.Get() // created, borrowed
+	p.Put(o)
+	if got := eventTypes(events); len(got) != 1 || got[0] != EventCreated {
+		t.Errorf("events=%v, want [%v]", got, EventCreated)
+	}
+	p.Close()
+}
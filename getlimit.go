@@ -0,0 +1,51 @@
+package pool
+
+import (
+	"context"
+	"time"
+)
+
+// enterGet 在同时调用Get()的goroutine达到MaxConcurrentGet时等待（Wait为true时）或返回ErrPoolExhausted
+func (p *Pool) enterGet(ctx context.Context) error {
+	p.mu.Lock()
+	if p.getSem == nil {
+		p.getSem = make(chan struct{}, p.MaxConcurrentGet)
+	}
+	sem, wait, timeout := p.getSem, p.Wait, p.WaitTimeout
+	select {
+	case sem <- struct{}{}:
+		p.mu.Unlock()
+		return nil
+	default:
+	}
+	if !wait {
+		p.stats.exhausted.Add(1)
+		err := p.err(ErrPoolExhausted)
+		p.mu.Unlock()
+		return err
+	}
+	p.mu.Unlock()
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		expired = t.C
+	}
+	select {
+	case sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-expired:
+		p.mu.Lock()
+		err := p.waitErr(ctx, context.DeadlineExceeded)
+		p.mu.Unlock()
+		return err
+	}
+}
+
+// leaveGet 释放enterGet获取的名额
+func (p *Pool) leaveGet() {
+	<-p.getSem
+}
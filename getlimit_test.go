@@ -0,0 +1,55 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPoolMaxConcurrentGet(t *testing.T) {
+	dialing := make(chan struct{}, 2)
+	unblock := make(chan struct{})
+	p := &Pool{
+		MaxIdle:          2,
+		MaxConcurrentGet: 1,
+		New: func() (interface{}, error) {
+			dialing <- struct{}{}
+			<-unblock
+			return &conn{}, nil
+		},
+	}
+	defer p.Close()
+
+	got := make(chan interface{})
+	go func() {
+		o, _ := p.Get()
+		got <- o
+	}()
+	<-dialing
+
+	if _, err := p.Get(); !errors.Is(err, ErrPoolExhausted) {
+		t.Errorf("Get()=%v, want ErrPoolExhausted", err)
+	}
+	p.mu.Lock()
+	p.Wait = true
+	p.mu.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := p.GetContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("GetContext()=%v, want DeadlineExceeded", err)
+	}
+
+	go func() {
+		o, _ := p.Get()
+		got <- o
+	}()
+	select {
+	case <-dialing:
+		t.Fatal("second Get() dialed while the first one was still in Get()")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(unblock)
+	p.Put(<-got)
+	p.Put(<-got)
+}
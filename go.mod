@@ -0,0 +1,3 @@
+module github.com/chen-zyc/pool
+
+go 1.21
@@ -0,0 +1,46 @@
+package pool
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// goroutineID 从runtime.Stack的输出中解析出当前goroutine的ID
+func goroutineID() int64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseInt(string(b), 10, 64)
+	return id
+}
+
+// callers 返回调用者的调用栈
+func callers() []uintptr {
+	pcs := make([]uintptr, 32)
+	return pcs[:runtime.Callers(2, pcs)]
+}
+
+var poolMethodPrefix = reflect.TypeOf(Pool{}).PkgPath() + ".(*Pool)."
+
+// formatStack 把callers的结果格式化成与panic时相同的形式，省略最前面pool自己的方法
+func formatStack(pcs []uintptr) string {
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	trim := true
+	for {
+		f, more := frames.Next()
+		if trim = trim && strings.HasPrefix(f.Function, poolMethodPrefix) && more; !trim {
+			fmt.Fprintf(&b, "%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+		}
+		if !more {
+			return b.String()
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package pool
+
+import "sync"
+
+// PoolGroup 让多个Pool共享一个总的MaxActive，每个Pool自己的MaxIdle和MaxActive仍然有效。
+// 和MaxActive一样，空闲对象也计入总数
+type PoolGroup struct {
+	mu      sync.Mutex
+	max     int // 为0时不限制
+	active  int
+	changed chan struct{} // active减少时关闭并重新创建，用于唤醒其他Pool中的等待者
+}
+
+func NewPoolGroup(globalMax int) *PoolGroup {
+	return &PoolGroup{max: globalMax, changed: make(chan struct{})}
+}
+
+// Add 把p加入group并返回p，p已有的对象也会计入总数
+func (g *PoolGroup) Add(p *Pool) *Pool {
+	p.mu.Lock()
+	p.group = g
+	active := p.active
+	p.mu.Unlock()
+
+	g.mu.Lock()
+	g.active += active
+	g.mu.Unlock()
+	return p
+}
+
+// ActiveCount 返回group中所有Pool的活跃对象总数
+func (g *PoolGroup) ActiveCount() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.active
+}
+
+// acquire 占用一个名额，失败时返回的channel会在有名额释放时关闭。g为nil时总是成功
+func (g *PoolGroup) acquire() (bool, <-chan struct{}) {
+	if g == nil {
+		return true, nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.max > 0 && g.active >= g.max {
+		return false, g.changed
+	}
+	g.active++
+	return true, nil
+}
+
+// release 释放n个名额
+func (g *PoolGroup) release(n int) {
+	if g == nil || n <= 0 {
+		return
+	}
+	g.mu.Lock()
+	g.active -= n
+	close(g.changed)
+	g.changed = make(chan struct{})
+	g.mu.Unlock()
+}
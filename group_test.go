@@ -0,0 +1,55 @@
+package pool
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPoolGroup(t *testing.T) {
+	d1, d2 := &poolDialer{t: t}, &poolDialer{t: t}
+	g := NewPoolGroup(2)
+	p1 := g.Add(NewPool(d1.dial, 2))
+	p2 := g.Add(NewPool(d2.dial, 2))
+	p1.DropCallback, p2.DropCallback = d1.drop, d2.drop
+	p1.MaxActive, p2.MaxActive = 2, 2
+
+	o1, _ := p1.Get()
+	o2, _ := p2.Get()
+	if n := g.ActiveCount(); n != 2 {
+		t.Errorf("ActiveCount()=%d, want 2", n)
+	}
+	if _, err := p1.Get(); !errors.Is(err, ErrGroupFull) || !errors.Is(err, ErrPoolExhausted) {
+		t.Errorf("err=%v, want %v", err, ErrGroupFull)
+	}
+
+	// p1中等待的goroutine在p2释放名额后被唤醒
+	p1.Wait = true
+	errs := make(chan error, 1)
+	go func() {
+		o, err := p1.Get()
+		if err == nil {
+			p1.Put(o)
+		}
+		errs <- err
+	}()
+	waitWaiters(t, p1, 1)
+	p2.PutErr(o2, errors.New("broken"))
+	select {
+	case err := <-errs:
+		if err != nil {
+			t.Errorf("err=%v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiter not woken")
+	}
+
+	p1.Put(o1)
+	p1.Close()
+	p2.Close()
+	if n := g.ActiveCount(); n != 0 {
+		t.Errorf("ActiveCount()=%d after close, want 0", n)
+	}
+	d1.check("p1 after close", p1, 2, 0)
+	d2.check("p2 after close", p2, 1, 0)
+}
@@ -0,0 +1,92 @@
+package pool
+
+import "time"
+
+// dialFailed 记录创建对象失败，调用时需持有锁
+func (p *Pool) dialFailed(err error) {
+	p.stats.errors.Add(1)
+	p.lastErr, p.lastErrAt = err, nowFunc()
+	p.recordError(err, "New")
+}
+
+// defaultErrorBufferSize 是ErrorBufferSize为0时AllErrors()保存的错误数
+const defaultErrorBufferSize = 32
+
+// RecordedError 是AllErrors()返回的一个错误
+type RecordedError struct {
+	Err    error
+	Time   time.Time
+	Source string // 产生错误的函数："New"、"TestOnBorrow"或"Ping"
+}
+
+func (e *RecordedError) Error() string {
+	return e.Source + ": " + e.Err.Error()
+}
+
+func (e *RecordedError) Unwrap() error {
+	return e.Err
+}
+
+// recordError 把错误保存到环形缓冲区中，调用时需持有锁
+func (p *Pool) recordError(err error, source string) {
+	size := p.ErrorBufferSize
+	if size <= 0 {
+		size = defaultErrorBufferSize
+	}
+	if len(p.errBuf) != size { // 第一次记录或者修改了ErrorBufferSize
+		p.errBuf, p.errNext, p.errCount = make([]RecordedError, size), 0, 0
+	}
+	p.errBuf[p.errNext] = RecordedError{Err: err, Time: nowFunc(), Source: source}
+	p.errNext = (p.errNext + 1) % size
+	if p.errCount < size {
+		p.errCount++
+	}
+}
+
+// AllErrors 返回最近的最多max个创建对象、TestOnBorrow或Ping失败的错误，最新的在前，
+// 元素的类型为*RecordedError。max小于等于0时返回所有保存的错误
+func (p *Pool) AllErrors(max int) []error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := p.errCount
+	if max > 0 && max < n {
+		n = max
+	}
+	errs := make([]error, 0, n)
+	for i := 1; i <= n; i++ {
+		e := p.errBuf[(p.errNext-i+len(p.errBuf))%len(p.errBuf)]
+		errs = append(errs, &e)
+	}
+	return errs
+}
+
+// ClearErrors 清除AllErrors()保存的错误
+func (p *Pool) ClearErrors() {
+	p.mu.Lock()
+	p.errBuf, p.errNext, p.errCount = nil, 0, 0
+	p.mu.Unlock()
+}
+
+// LastError 返回最近一次创建对象失败的错误，没有失败过或Reset之后返回nil
+func (p *Pool) LastError() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.lastErr
+}
+
+// IsHealthy 不进行I/O，只根据pool的状态判断能否提供对象：pool已关闭、最近ErrorRecencyWindow内创建对象失败过、
+// 或者Wait为false时已经达到MaxActive且没有空闲对象，都返回false。需要实际检查对象时使用HealthCheck()
+func (p *Pool) IsHealthy() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return false
+	}
+	if p.lastErr != nil && p.ErrorRecencyWindow > 0 && nowFunc().Sub(p.lastErrAt) < p.ErrorRecencyWindow {
+		return false
+	}
+	if max := p.maxActive(); max > 0 && p.active >= max && p.idle.Len() == 0 && !p.Wait {
+		return false
+	}
+	return true
+}
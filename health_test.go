@@ -0,0 +1,99 @@
+package pool
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPoolIsHealthy(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 1)
+	p.MaxActive = 1
+	p.ErrorRecencyWindow = time.Minute
+
+	now := time.Now()
+	nowFunc = func() time.Time {
+		return now
+	}
+	defer func() {
+		nowFunc = time.Now
+	}()
+
+	if !p.IsHealthy() {
+		t.Error("new pool is not healthy")
+	}
+	o, _ := p.Get()
+	if p.IsHealthy() {
+		t.Error("exhausted pool is healthy")
+	}
+	p.Put(o)
+	if !p.IsHealthy() {
+		t.Error("pool with idle object is not healthy")
+	}
+
+	p.Reset()
+	refused := errors.New("refused")
+	p.New = func() (interface{}, error) { return nil, refused }
+	if _, err := p.Get(); err != refused {
+		t.Fatalf("Get()=%v, want %v", err, refused)
+	}
+	if err := p.LastError(); err != refused {
+		t.Errorf("LastError()=%v, want %v", err, refused)
+	}
+	if p.IsHealthy() {
+		t.Error("pool is healthy right after a dial error")
+	}
+	now = now.Add(time.Minute)
+	if !p.IsHealthy() {
+		t.Error("pool is not healthy after ErrorRecencyWindow")
+	}
+
+	p.Close()
+	if p.IsHealthy() {
+		t.Error("closed pool is healthy")
+	}
+}
+
+func TestPoolAllErrors(t *testing.T) {
+	dialErr := errors.New("dial failed")
+	testErr := errors.New("test failed")
+	fail := true
+	d := &poolDialer{t: t}
+	p := NewPool(func() (interface{}, error) {
+		if fail {
+			return nil, dialErr
+		}
+		return d.dial()
+	}, 1)
+	p.ErrorBufferSize = 2
+	p.TestOnBorrow = func(interface{}) error { return testErr }
+	defer p.Close()
+
+	p.Get()
+	p.Get()
+	fail = false
+	o, _ := p.Get()
+	p.Put(o)
+	p.Get() // TestOnBorrow失败后创建新对象
+
+	errs := p.AllErrors(0)
+	if len(errs) != 2 {
+		t.Fatalf("AllErrors()=%v, want 2 errors", errs)
+	}
+	var re *RecordedError
+	if !errors.As(errs[0], &re) || re.Source != "TestOnBorrow" || !errors.Is(errs[0], testErr) || re.Time.IsZero() {
+		t.Errorf("AllErrors()[0]=%v, want the TestOnBorrow error", errs[0])
+	}
+	if !errors.As(errs[1], &re) || re.Source != "New" || !errors.Is(errs[1], dialErr) {
+		t.Errorf("AllErrors()[1]=%v, want the New error", errs[1])
+	}
+	if errs := p.AllErrors(1); len(errs) != 1 || !errors.Is(errs[0], testErr) {
+		t.Errorf("AllErrors(1)=%v", errs)
+	}
+
+	p.ClearErrors()
+	if errs := p.AllErrors(0); len(errs) != 0 {
+		t.Errorf("AllErrors() after ClearErrors()=%v", errs)
+	}
+}
@@ -0,0 +1,32 @@
+package pool
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+type statsResponse struct {
+	PoolStats
+	Active  int `json:"active"`
+	Idle    int `json:"idle"`
+	Waiters int `json:"waiters"`
+}
+
+// ServeHTTP 以JSON格式输出pool的统计数据
+func (p *Pool) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	resp := statsResponse{PoolStats: p.Stats()}
+	p.mu.Lock()
+	resp.Active = p.active
+	resp.Idle = p.idle.Len()
+	resp.Waiters = len(p.waiters)
+	p.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// RegisterHandler 把p注册到mux的path上
+func RegisterHandler(mux *http.ServeMux, path string, p *Pool) {
+	mux.Handle(path, p)
+}
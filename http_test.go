@@ -0,0 +1,41 @@
+package pool
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPoolServeHTTP(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	defer p.Close()
+
+	o1, _ := p.Get()
+	o2, _ := p.Get()
+	p.Put(o1)
+	o1, _ = p.Get()
+	p.Put(o1)
+
+	mux := http.NewServeMux()
+	RegisterHandler(mux, "/pool/stats", p)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/pool/stats", nil))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("code=%d, want %d", w.Code, http.StatusOK)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type=%q, want application/json", ct)
+	}
+	var resp statsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	want := statsResponse{PoolStats: PoolStats{Hits: 1, Misses: 2}, Active: 2, Idle: 1}
+	if resp != want {
+		t.Errorf("resp=%+v, want %+v", resp, want)
+	}
+	p.Put(o2)
+}
@@ -0,0 +1,196 @@
+package pool
+
+import (
+	"context"
+	"reflect"
+)
+
+// Compact 从最旧的开始丢弃空闲对象，直到空闲对象不超过target个，返回丢弃的数量。
+// target小于0时，空闲对象数不超过当前借出的对象数
+func (p *Pool) Compact(target int) int {
+	p.mu.Lock()
+	if target < 0 {
+		target = p.active - p.idle.Len()
+	}
+	objs := p.evictOldest(p.idle.Len() - target)
+	p.dropObjs(objs...)
+	return len(objs)
+}
+
+// Drain 丢弃所有空闲对象（包括空闲超过MaxIdleTime的），但不关闭pool，返回丢弃的数量
+func (p *Pool) Drain() int {
+	p.mu.Lock()
+	objs := p.takeIdle()
+	for _, obj := range objs {
+		p.release()
+		p.evict(obj, "drained")
+	}
+	p.dropObjs(objs...)
+	return len(objs)
+}
+
+// WaitForIdle 阻塞直到至少有n个空闲对象，ctx被取消时返回ctx.Err()，pool关闭时返回ErrPoolClosed。
+// 用于测试或批处理中确认借出的对象都已经放回
+func (p *Pool) WaitForIdle(ctx context.Context, n int) error {
+	p.mu.Lock()
+	for {
+		if p.idle.Len() >= n {
+			p.mu.Unlock()
+			return nil
+		}
+		if p.closed {
+			err := p.err(ErrPoolClosed)
+			p.mu.Unlock()
+			return err
+		}
+		ch := p.idleChanged()
+		p.mu.Unlock()
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		p.mu.Lock()
+	}
+}
+
+// idleChanged 返回空闲对象或active可能变化时关闭的channel，调用时需持有锁
+func (p *Pool) idleChanged() <-chan struct{} {
+	if p.idleCh == nil {
+		p.idleCh = make(chan struct{})
+	}
+	return p.idleCh
+}
+
+// notifyIdle 唤醒所有WaitForIdle和Shutdown，它们会重新检查空闲对象和active的数量，调用时需持有锁
+func (p *Pool) notifyIdle() {
+	if p.idleCh != nil {
+		close(p.idleCh)
+		p.idleCh = nil
+	}
+}
+
+// CloseIdleConnections 与Drain相同，和http.Transport的同名方法对应
+func (p *Pool) CloseIdleConnections() {
+	p.Drain()
+}
+
+// evictOldest 从最旧的开始移除n个空闲对象并返回它们，调用时需持有锁
+func (p *Pool) evictOldest(n int) []interface{} {
+	var objs []interface{}
+	for ; n > 0 && p.idle.Len() > 0; n-- {
+		io := p.idle.Remove(p.idle.Back())
+		obj := io.Obj
+		p.retire(io)
+		p.evict(obj, "compacted")
+		objs = append(objs, obj)
+	}
+	return objs
+}
+
+// Copy 把空闲对象从p移到dst，不超过dst的MaxIdle和MaxActive，返回移动的数量。
+// 对象会保留原来的放回时间和过期时间
+func (p *Pool) Copy(dst *Pool) int {
+	return p.CopyTo(dst, -1)
+}
+
+// CopyTo 与Copy相同，但最多移动n个空闲对象，n小于0时不限制，
+// 用于淘汰一个pool时把部分连接交给另一个连接同一个后端的pool。对象会保留创建时间、使用次数等信息
+func (p *Pool) CopyTo(dst *Pool, n int) int {
+	if p == dst || n == 0 {
+		return 0
+	}
+	// 按地址顺序加锁，避免两个pool同时互相Copy时死锁
+	first, second := p, dst
+	if reflect.ValueOf(first).Pointer() > reflect.ValueOf(second).Pointer() {
+		first, second = second, first
+	}
+	first.mu.Lock()
+	defer first.mu.Unlock()
+	second.mu.Lock()
+	defer second.mu.Unlock()
+
+	if dst.closed {
+		return 0
+	}
+	sameGroup := p.group == dst.group // 在同一个PoolGroup中时总数不变
+	moved := 0
+	for (n < 0 || moved < n) && p.idle.Len() > 0 && dst.idle.Len() < dst.maxIdle() && (dst.maxActive() == 0 || dst.active < dst.maxActive()) {
+		if !sameGroup {
+			if ok, _ := dst.group.acquire(); !ok {
+				break
+			}
+		}
+		io := p.idle.Remove(p.idle.Front())
+		p.active--
+		if !sameGroup {
+			p.group.release(1)
+		}
+		p.signal()
+		dst.active++
+		dst.idle.PushBack(io)
+		dst.signalIdle()
+		moved++
+	}
+	return moved
+}
+
+// ExportIdle 移除并返回所有空闲对象（包括空闲超过MaxIdleTime的），不会调用DropCallback，
+// 对象交给调用者处理，如进程重启时把连接的fd传给新的进程
+func (p *Pool) ExportIdle() []interface{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	objs := p.takeIdle()
+	for range objs {
+		p.release()
+	}
+	return objs
+}
+
+// ImportIdle 把已有的对象作为空闲对象放入pool，创建时间记为当前时间。
+// 超出MaxIdle或MaxActive的对象以及pool已关闭时的所有对象会被丢弃（调用DropCallback）
+func (p *Pool) ImportIdle(objs []interface{}) {
+	infos := make([]ConnectionInfo, len(objs))
+	for i, obj := range objs {
+		infos[i].Obj = obj
+	}
+	p.importIdle(infos, false)
+}
+
+// importIdle 把infos作为空闲对象放入pool，CreatedAt为零值时记为当前时间，放不下的对象会被丢弃。
+// keepVersion为false时对象记录当前的ConnectionVersion，否则保留原来的版本，版本已经过时的对象会被丢弃
+func (p *Pool) importIdle(infos []ConnectionInfo, keepVersion bool) {
+	p.mu.Lock()
+	tagFunc := p.Tag
+	p.mu.Unlock()
+	if tagFunc != nil {
+		for i := range infos {
+			infos[i].tag = tagFunc(infos[i].Obj)
+		}
+	}
+
+	p.mu.Lock()
+	var dropped []interface{}
+	for _, io := range infos {
+		if p.closed || p.idle.Len() >= p.maxIdle() || (p.maxActive() > 0 && p.active >= p.maxActive()) || (keepVersion && io.version < p.ConnectionVersion) {
+			dropped = append(dropped, io.Obj)
+			continue
+		}
+		if ok, _ := p.group.acquire(); !ok {
+			dropped = append(dropped, io.Obj)
+			continue
+		}
+		io.IdleSince = nowFunc()
+		if io.CreatedAt.IsZero() {
+			io.CreatedAt = io.IdleSince
+		}
+		p.setExpires(&io)
+		if !keepVersion {
+			io.version = p.ConnectionVersion
+		}
+		p.active++
+		p.idle.PushFront(io)
+		p.signal()
+	}
+	p.dropObjs(dropped...)
+}
@@ -0,0 +1,365 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPoolCompact(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 4)
+	p.DropCallback = d.drop
+
+	var objs []interface{}
+	for i := 0; i < 4; i++ {
+		o, _ := p.Get()
+		objs = append(objs, o)
+	}
+	for _, o := range objs[1:] {
+		p.Put(o)
+	}
+	d.check("before compact", p, 4, 4)
+
+	if n := p.Compact(2); n != 1 {
+		t.Errorf("removed=%d, want 1", n)
+	}
+	d.check("compact 2", p, 4, 3)
+
+	if n := p.Compact(-1); n != 1 {
+		t.Errorf("removed=%d, want 1", n)
+	}
+	d.check("compact -1", p, 4, 2)
+
+	p.Put(objs[0])
+	p.Close()
+	d.check("after close", p, 4, 0)
+}
+
+func TestPoolCopy(t *testing.T) {
+	d := &poolDialer{t: t}
+	src := NewPool(d.dial, 3)
+	src.DropCallback = d.drop
+	dst := NewPool(d.dial, 2)
+	dst.DropCallback = d.drop
+
+	var objs []interface{}
+	for i := 0; i < 3; i++ {
+		o, _ := src.Get()
+		objs = append(objs, o)
+	}
+	for _, o := range objs {
+		src.Put(o)
+	}
+
+	if n := src.Copy(dst); n != 2 {
+		t.Errorf("Copy()=%d, want 2", n)
+	}
+	if n := src.IdleCount(); n != 1 {
+		t.Errorf("src IdleCount()=%d, want 1", n)
+	}
+	if n := dst.ActiveCount(); n != 2 {
+		t.Errorf("dst ActiveCount()=%d, want 2", n)
+	}
+	if o, _ := dst.Get(); o != objs[2] { // 新放回的对象仍然在前面
+		t.Errorf("dst.Get()=%v, want %v", o, objs[2])
+	}
+	if n := dst.Copy(src); n != 1 {
+		t.Errorf("Copy()=%d, want 1", n)
+	}
+	src.Close()
+	dst.Close()
+	if d.open != 1 { // dst中还有一个借出的对象
+		t.Errorf("open=%d, want 1", d.open)
+	}
+}
+
+func TestPoolCopyTo(t *testing.T) {
+	d := &poolDialer{t: t}
+	src := NewPool(d.dial, 3)
+	dst := NewPool(d.dial, 3)
+	defer src.Close()
+
+	var objs []interface{}
+	for i := 0; i < 3; i++ {
+		o, _ := src.Get()
+		objs = append(objs, o)
+	}
+	for _, o := range objs {
+		src.Put(o)
+	}
+
+	if n := src.CopyTo(dst, 2); n != 2 {
+		t.Errorf("CopyTo(2)=%d, want 2", n)
+	}
+	if n := src.IdleCount(); n != 1 {
+		t.Errorf("src IdleCount()=%d, want 1", n)
+	}
+	dst.Close()
+	if n := src.CopyTo(dst, 1); n != 0 {
+		t.Errorf("CopyTo(closed)=%d, want 0", n)
+	}
+	if n := src.IdleCount(); n != 1 {
+		t.Errorf("src IdleCount()=%d after copying to a closed pool, want 1", n)
+	}
+}
+
+func TestPoolMaxIdlePercent(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 10)
+	p.DropCallback = d.drop
+	p.MaxActive = 4
+	p.MaxIdlePercent = 0.5
+
+	var objs []interface{}
+	for i := 0; i < 4; i++ {
+		o, _ := p.Get()
+		objs = append(objs, o)
+	}
+	for _, o := range objs {
+		p.Put(o)
+	}
+	d.check("half of MaxActive", p, 4, 2)
+
+	p.MaxActive = 8
+	for i := 0; i < 4; i++ {
+		o, _ := p.Get()
+		objs[i] = o
+	}
+	for _, o := range objs {
+		p.Put(o)
+	}
+	d.check("MaxActive changed", p, 6, 4)
+	p.Close()
+}
+
+func TestPoolDrain(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 3)
+	p.DropCallback = d.drop
+
+	o1, _ := p.Get()
+	o2, _ := p.Get()
+	o3, _ := p.Get()
+	p.Put(o1)
+	p.Put(o2)
+	if n := p.Drain(); n != 2 {
+		t.Errorf("Drain()=%d, want 2", n)
+	}
+	d.check("drain", p, 3, 1)
+
+	p.Put(o3) // 没有关闭pool
+	if n := p.IdleCount(); n != 1 {
+		t.Errorf("IdleCount()=%d, want 1", n)
+	}
+	p.CloseIdleConnections()
+	d.check("close idle connections", p, 3, 0)
+	if _, err := p.Get(); err != nil {
+		t.Errorf("Get()=%v after CloseIdleConnections", err)
+	}
+	p.Close()
+}
+
+func TestPoolWaitForIdle(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 3)
+
+	var objs []interface{}
+	for i := 0; i < 3; i++ {
+		o, _ := p.Get()
+		objs = append(objs, o)
+	}
+	for _, o := range objs {
+		go func(o interface{}) {
+			time.Sleep(10 * time.Millisecond)
+			p.Put(o)
+		}(o)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := p.WaitForIdle(ctx, 3); err != nil {
+		t.Fatalf("WaitForIdle(3)=%v", err)
+	}
+	if n := p.IdleCount(); n != 3 {
+		t.Errorf("IdleCount()=%d, want 3", n)
+	}
+
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel2()
+	if err := p.WaitForIdle(ctx2, 4); err != context.DeadlineExceeded {
+		t.Errorf("WaitForIdle(4)=%v, want DeadlineExceeded", err)
+	}
+
+	go p.Close()
+	if err := p.WaitForIdle(context.Background(), 4); err != ErrPoolClosed {
+		t.Errorf("WaitForIdle() after close: %v, want ErrPoolClosed", err)
+	}
+}
+
+func TestPoolExportImportIdle(t *testing.T) {
+	d := &poolDialer{t: t}
+	src := NewPool(d.dial, 3)
+	src.DropCallback = d.drop
+	dst := NewPool(d.dial, 2)
+	dst.DropCallback = d.drop
+
+	var objs []interface{}
+	for i := 0; i < 3; i++ {
+		o, _ := src.Get()
+		objs = append(objs, o)
+	}
+	for _, o := range objs {
+		src.Put(o)
+	}
+
+	exported := src.ExportIdle()
+	if len(exported) != 3 || src.IdleCount() != 0 || src.ActiveCount() != 0 {
+		t.Errorf("ExportIdle()=%v, idle=%d, active=%d", exported, src.IdleCount(), src.ActiveCount())
+	}
+	if d.open != 3 { // 导出的对象没有被关闭
+		t.Errorf("open=%d, want 3", d.open)
+	}
+
+	dst.ImportIdle(exported)
+	if n := dst.IdleCount(); n != 2 {
+		t.Errorf("dst IdleCount()=%d, want 2", n)
+	}
+	d.check("after import", dst, 3, 2) // 超出MaxIdle的被丢弃
+	if o, _ := dst.Get(); o != exported[1] {
+		t.Errorf("dst.Get()=%v, want %v", o, exported[1])
+	}
+	src.Close()
+	dst.Close()
+}
+
+func TestPoolRecycle(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+	p.IdleTimeout = time.Minute
+	var recycled []interface{}
+	p.Recycle = func(obj interface{}) interface{} {
+		recycled = append(recycled, obj)
+		if len(recycled) == 1 {
+			return &conn{id: 100} // 重置后继续使用
+		}
+		return nil // 交给调用者处理
+	}
+	o := &recordObserver{}
+	p.SetObserver(o)
+	defer p.Close()
+
+	now := time.Now()
+	nowFunc = func() time.Time {
+		return now
+	}
+	defer func() {
+		nowFunc = time.Now
+	}()
+
+	o1, _ := p.Get()
+	o2, _ := p.Get()
+	p.Put(o1)
+	p.Put(o2)
+	now = now.Add(2 * time.Minute) // 过期的对象调用Recycle
+	c, _ := p.Get()
+	if c.(*conn).id != 100 {
+		t.Errorf("Get()=%v, want the recycled object", c)
+	}
+	if len(recycled) != 2 || recycled[0] != o1 || recycled[1] != o2 {
+		t.Errorf("recycled %v, want [%v %v]", recycled, o1, o2)
+	}
+	if d.open != 2 { // 没有调用DropCallback
+		t.Errorf("open=%d, want 2", d.open)
+	}
+	for _, call := range o.calls {
+		if strings.HasPrefix(call, "destroy") {
+			t.Errorf("recycled objects reported as destroyed: %v", o.calls)
+			break
+		}
+	}
+	p.Put(c)
+	p.SetMaxIdle(0) // 超出MaxIdle的对象同样调用Recycle
+	if len(recycled) != 3 || recycled[2] != c {
+		t.Errorf("recycled %v, want %v last", recycled, c)
+	}
+	p.SetMaxIdle(2)
+
+	// Compact、Drain等主动丢弃的对象直接调用DropCallback
+	o3, _ := p.Get()
+	p.Put(o3)
+	if n := p.Compact(0); n != 1 {
+		t.Errorf("Compact(0)=%d, want 1", n)
+	}
+	if n := p.IdleCount(); n != 0 {
+		t.Errorf("IdleCount() after Compact(0)=%d, want 0", n)
+	}
+	if len(recycled) != 3 || d.open != 2 {
+		t.Errorf("Compact(0): recycled %d, open=%d, want 3, 2", len(recycled), d.open)
+	}
+
+	o4, _ := p.Get()
+	p.PutErr(o4, errors.New("broken")) // 借出后丢弃的对象不调用Recycle
+	if len(recycled) != 3 {
+		t.Errorf("Recycle called for a borrowed object")
+	}
+}
+
+func TestPoolRecycleVersion(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+	p.IdleTimeout = time.Minute
+	p.ConnectionVersion = 1
+	p.Recycle = func(obj interface{}) interface{} { return obj }
+	defer p.Close()
+
+	now := time.Now()
+	nowFunc = func() time.Time {
+		return now
+	}
+	defer func() {
+		nowFunc = time.Now
+	}()
+
+	o1, _ := p.Get()
+	p.Put(o1)
+	p.SetVersion(2)
+	now = now.Add(2 * time.Minute) // 过期时版本已经过时，不再回收
+	o2, _ := p.Get()
+	if o2 == o1 {
+		t.Errorf("Get() returned an object of an old version")
+	}
+	d.check("old version", p, 2, 1)
+
+	created := now
+	p.Put(o2)
+	now = now.Add(2 * time.Minute) // 回收的对象保留创建时间和版本
+	p.mu.Lock()
+	expired := p.removeExpired()
+	p.dropObjs(expired...)
+	p.mu.Lock()
+	if p.idle.Len() != 1 {
+		t.Fatalf("idle=%d, want 1", p.idle.Len())
+	}
+	io := p.idle.Front().Value
+	p.mu.Unlock()
+	if io.Obj != o2 || !io.CreatedAt.Equal(created) || io.version != 2 {
+		t.Errorf("recycled %v created at %v version %d, want %v created at %v version 2", io.Obj, io.CreatedAt, io.version, o2, created)
+	}
+}
+
+func TestPoolDropUnhashable(t *testing.T) {
+	dropped := 0
+	p := NewPool(func() (interface{}, error) { return []int{1}, nil }, 1)
+	p.DropCallback = func(interface{}) { dropped++ }
+	defer p.Close()
+
+	o, _ := p.Get()
+	p.PutErr(o, errors.New("broken")) // 没有设置Recycle时对象不需要能作为map的key
+	if dropped != 1 {
+		t.Errorf("dropped=%d, want 1", dropped)
+	}
+}
@@ -0,0 +1,367 @@
+package pool
+
+import "container/heap"
+
+// idleList 是保存空闲对象的双向链表，用法和container/list相同，零值可以直接使用。
+// 元素直接保存ConnectionInfo，移除的元素会留给之后的PushFront、PushBack复用，
+// 对象放回pool时不需要分配内存。
+// MaxIdle较小时也使用idleList，没有另外提供固定大小的环形缓冲区（UseRingBuffer）：空闲对象会从链表中间移除（标签、等级、分数、Filter等），
+// MaxIdle也可以在运行时修改，而复用元素已经去掉了所有配置下的内存分配，见BenchmarkIdleListSmallMaxIdle。
+// 每个等级（ConnectionInfo.tier）的元素保存在单独的链表中，遍历时先遍历等级小的，
+// Front返回等级最小的元素中最新的，Back返回等级最大的元素中最旧的，都不需要遍历。
+// 第一次调用Best之后每个等级的元素还会按分数保存在堆中。
+// 设置了过期时间的元素保存在按过期时间排列的堆中，第一次调用Oldest之后所有元素还会按IdleSince保存在堆中，
+// 清除过期对象时只需要查看堆顶
+type idleList struct {
+	roots  [maxTier]idleElem // 每个等级的哨兵，roots[t].next是等级t的第一个元素，roots[t].prev是最后一个
+	len    int
+	free   *idleElem // 可以复用的元素，通过next串起来
+	seq    uint64    // 最近一次插入的元素的序号
+	scored bool      // 是否在维护heaps
+	heaps  [maxTier]scoreHeap
+	expiry expiryHeap // 设置了过期时间的元素
+	aged   bool       // 是否在维护ages
+	ages   ageHeap
+}
+
+// idleRef 记录元素和它插入时的序号，释放锁之后用来判断元素是否仍然保存着同一个对象
+type idleRef struct {
+	e   *idleElem
+	seq uint64
+}
+
+// in 返回元素是否仍然在l中并且没有被其他对象复用
+func (r idleRef) in(l *idleList) bool {
+	return r.e.list == l && r.e.seq == r.seq
+}
+
+type idleElem struct {
+	next, prev *idleElem
+	list       *idleList
+	tier       int    // 所在的链表
+	seq        uint64 // 插入时的序号，元素被复用后会变化
+	index      int    // 在heaps[tier]中的位置
+	eindex     int    // 在expiry中的位置，没有过期时间时为-1
+	aindex     int    // 在ages中的位置
+	Value      ConnectionInfo
+}
+
+// Next 返回下一个元素，没有时返回nil
+func (e *idleElem) Next() *idleElem {
+	l := e.list
+	if l == nil {
+		return nil
+	}
+	if p := e.next; p != &l.roots[e.tier] {
+		return p
+	}
+	for t := e.tier + 1; t < maxTier; t++ {
+		if l.roots[t].next != &l.roots[t] {
+			return l.roots[t].next
+		}
+	}
+	return nil
+}
+
+// Prev 返回上一个元素，没有时返回nil
+func (e *idleElem) Prev() *idleElem {
+	l := e.list
+	if l == nil {
+		return nil
+	}
+	if p := e.prev; p != &l.roots[e.tier] {
+		return p
+	}
+	for t := e.tier - 1; t >= 0; t-- {
+		if l.roots[t].prev != &l.roots[t] {
+			return l.roots[t].prev
+		}
+	}
+	return nil
+}
+
+// Init 清空链表，已有的元素不会被复用
+func (l *idleList) Init() *idleList {
+	for t := range l.roots {
+		l.roots[t].next = &l.roots[t]
+		l.roots[t].prev = &l.roots[t]
+		l.heaps[t] = nil
+	}
+	l.expiry = nil
+	l.ages = nil
+	l.len = 0
+	return l
+}
+
+func (l *idleList) lazyInit() {
+	if l.roots[0].next == nil {
+		l.Init()
+	}
+}
+
+func (l *idleList) Len() int { return l.len }
+
+func (l *idleList) Front() *idleElem {
+	if l.len == 0 {
+		return nil
+	}
+	for t := range l.roots {
+		if l.roots[t].next != &l.roots[t] {
+			return l.roots[t].next
+		}
+	}
+	return nil
+}
+
+func (l *idleList) Back() *idleElem {
+	if l.len == 0 {
+		return nil
+	}
+	for t := maxTier - 1; t >= 0; t-- {
+		if l.roots[t].prev != &l.roots[t] {
+			return l.roots[t].prev
+		}
+	}
+	return nil
+}
+
+// TierBack 返回等级为tier的元素中最后一个（最旧的），没有时返回nil
+func (l *idleList) TierBack(tier int) *idleElem {
+	if l.len == 0 || l.roots[tier].prev == &l.roots[tier] {
+		return nil
+	}
+	return l.roots[tier].prev
+}
+
+// TopBack 返回等级最小的元素中最后一个（最旧的），没有元素时返回nil
+func (l *idleList) TopBack() *idleElem {
+	if e := l.Front(); e != nil {
+		return l.roots[e.tier].prev
+	}
+	return nil
+}
+
+// PushFront 把v放到它的等级的最前面
+func (l *idleList) PushFront(v ConnectionInfo) *idleElem {
+	l.lazyInit()
+	t := clampTier(v.tier)
+	return l.insert(v, &l.roots[t], t)
+}
+
+// PushBack 把v放到它的等级的最后面
+func (l *idleList) PushBack(v ConnectionInfo) *idleElem {
+	l.lazyInit()
+	t := clampTier(v.tier)
+	return l.insert(v, l.roots[t].prev, t)
+}
+
+// InsertBefore 把v插入到mark之前，mark必须在l中，v的等级需要和mark相同
+func (l *idleList) InsertBefore(v ConnectionInfo, mark *idleElem) *idleElem {
+	return l.insert(v, mark.prev, mark.tier)
+}
+
+// insert 把v插入到等级为tier的链表中的at之后
+func (l *idleList) insert(v ConnectionInfo, at *idleElem, tier int) *idleElem {
+	e := l.free
+	if e != nil {
+		l.free = e.next
+	} else {
+		e = &idleElem{}
+	}
+	l.seq++
+	e.Value = v
+	e.list = l
+	e.tier = tier
+	e.seq = l.seq
+	e.prev = at
+	e.next = at.next
+	e.prev.next = e
+	e.next.prev = e
+	l.len++
+	if l.scored {
+		heap.Push(&l.heaps[tier], e)
+	}
+	e.eindex = -1
+	if !v.expires.IsZero() {
+		heap.Push(&l.expiry, e)
+	}
+	if l.aged {
+		heap.Push(&l.ages, e)
+	}
+	return e
+}
+
+// Remove 移除e并返回它保存的ConnectionInfo，e不在l中时只返回它的值
+func (l *idleList) Remove(e *idleElem) ConnectionInfo {
+	v := e.Value
+	if e.list != l {
+		return v
+	}
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	l.len--
+	if l.scored {
+		heap.Remove(&l.heaps[e.tier], e.index)
+	}
+	if e.eindex >= 0 {
+		heap.Remove(&l.expiry, e.eindex)
+	}
+	if l.aged {
+		heap.Remove(&l.ages, e.aindex)
+	}
+	e.Value = ConnectionInfo{} // 不再引用对象
+	e.list, e.prev = nil, nil
+	e.next = l.free
+	l.free = e
+	return v
+}
+
+// Best 返回等级最小的元素中分数最高的，分数相同时返回较新的，没有元素时返回nil。
+// 第一次调用时建立每个等级的堆，之后插入、移除和SetScore都会维护它们
+func (l *idleList) Best() *idleElem {
+	if l.len == 0 {
+		return nil
+	}
+	if !l.scored {
+		l.scored = true
+		for t := range l.roots {
+			h := l.heaps[t][:0]
+			for e := l.roots[t].next; e != &l.roots[t]; e = e.next {
+				e.index = len(h)
+				h = append(h, e)
+			}
+			l.heaps[t] = h
+			heap.Init(&l.heaps[t])
+		}
+	}
+	for t := range l.heaps {
+		if len(l.heaps[t]) > 0 {
+			return l.heaps[t][0]
+		}
+	}
+	return nil
+}
+
+// Expiring 返回设置了过期时间的元素中最早过期的，没有时返回nil
+func (l *idleList) Expiring() *idleElem {
+	if len(l.expiry) == 0 {
+		return nil
+	}
+	return l.expiry[0]
+}
+
+// Oldest 返回IdleSince最早的元素，没有元素时返回nil。
+// 第一次调用时建立按IdleSince排列的堆，之后插入和移除都会维护它
+func (l *idleList) Oldest() *idleElem {
+	if l.len == 0 {
+		return nil
+	}
+	if !l.aged {
+		l.aged = true
+		h := l.ages[:0]
+		for e := l.Front(); e != nil; e = e.Next() {
+			e.aindex = len(h)
+			h = append(h, e)
+		}
+		l.ages = h
+		heap.Init(&l.ages)
+	}
+	return l.ages[0]
+}
+
+// SetScore 修改e的分数，e必须在l中
+func (l *idleList) SetScore(e *idleElem, score float64) {
+	e.Value.score = score
+	if l.scored {
+		heap.Fix(&l.heaps[e.tier], e.index)
+	}
+}
+
+// scoreHeap 是按分数排列的堆，分数相同时较新放回的在前面
+type scoreHeap []*idleElem
+
+func (h scoreHeap) Len() int { return len(h) }
+
+func (h scoreHeap) Less(i, j int) bool {
+	a, b := h[i], h[j]
+	if a.Value.score != b.Value.score {
+		return a.Value.score > b.Value.score
+	}
+	if !a.Value.IdleSince.Equal(b.Value.IdleSince) {
+		return a.Value.IdleSince.After(b.Value.IdleSince)
+	}
+	return a.seq > b.seq
+}
+
+func (h scoreHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *scoreHeap) Push(x interface{}) {
+	e := x.(*idleElem)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *scoreHeap) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return e
+}
+
+// expiryHeap 是按过期时间排列的堆，最早过期的在最前面
+type expiryHeap []*idleElem
+
+func (h expiryHeap) Len() int           { return len(h) }
+func (h expiryHeap) Less(i, j int) bool { return h[i].Value.expires.Before(h[j].Value.expires) }
+
+func (h expiryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].eindex = i
+	h[j].eindex = j
+}
+
+func (h *expiryHeap) Push(x interface{}) {
+	e := x.(*idleElem)
+	e.eindex = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *expiryHeap) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	e.eindex = -1
+	return e
+}
+
+// ageHeap 是按IdleSince排列的堆，最旧的在最前面
+type ageHeap []*idleElem
+
+func (h ageHeap) Len() int           { return len(h) }
+func (h ageHeap) Less(i, j int) bool { return h[i].Value.IdleSince.Before(h[j].Value.IdleSince) }
+
+func (h ageHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].aindex = i
+	h[j].aindex = j
+}
+
+func (h *ageHeap) Push(x interface{}) {
+	e := x.(*idleElem)
+	e.aindex = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *ageHeap) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return e
+}
@@ -0,0 +1,16 @@
+package pool
+
+// PoolIface 是Pool对外提供的基本方法，便于在测试中替换成mock
+type PoolIface interface {
+	Get() (interface{}, error)
+	Put(interface{})
+	Close()
+	OnceClose()
+	ActiveCount() int
+}
+
+var (
+	_ PoolIface = (*Pool)(nil)
+	_ PoolIface = (*MirroredPool)(nil)
+	_ PoolIface = (*ScopedPool)(nil)
+)
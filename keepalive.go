@@ -0,0 +1,159 @@
+package pool
+
+import (
+	"context"
+	"time"
+)
+
+// keepalive 对所有空闲对象调用Ping，失败的对象会被丢弃
+func (p *Pool) keepalive() {
+	p.mu.Lock()
+	ping := p.Ping
+	p.mu.Unlock()
+	if ping != nil {
+		p.checkIdle(ping, "Ping")
+	}
+}
+
+// checkIdleHealth 在后台对所有空闲对象调用TestOnBorrow，失败的对象会被丢弃
+func (p *Pool) checkIdleHealth() {
+	p.mu.Lock()
+	test := p.TestOnBorrow
+	p.mu.Unlock()
+	if test != nil {
+		p.checkIdle(test, "TestOnBorrow")
+	}
+}
+
+// checkIdle 从最旧的开始每次取出一个空闲对象调用check，通过的放回原来的位置，失败的丢弃，source是check的名字。
+// 检查期间其他空闲对象仍然可以借出，每次最多检查开始时的空闲对象数个。
+// 先检查开始时的空闲对象，再检查一次期间放回的还没有检查过的对象，每一轮最多遍历两次空闲列表
+func (p *Pool) checkIdle(check func(interface{}) error, source string) {
+	p.mu.Lock()
+	p.checkRound++
+	round, n := p.checkRound, p.idle.Len()
+	refs, seq := p.uncheckedIdle(round, 0)
+	p.mu.Unlock()
+
+	var checked [maxTier][]idleRef // 每个等级中这一轮检查过的对象，最后一个是最新的
+	for pass := 0; pass < 2; pass++ {
+		for _, r := range refs {
+			if n == 0 {
+				return
+			}
+			p.mu.Lock()
+			if p.closed {
+				p.mu.Unlock()
+				return
+			}
+			if !r.in(&p.idle) { // 已经被借出了，放回时是新的元素
+				p.mu.Unlock()
+				continue
+			}
+			n--
+			io := p.idle.Remove(r.e)
+			p.mu.Unlock()
+
+			err := check(io.Obj)
+
+			p.mu.Lock()
+			if err == nil && !p.closed && p.idle.Len() < p.maxIdle() {
+				io.checked = round
+				p.putChecked(io, &checked)
+				p.signal()
+				p.mu.Unlock()
+				continue
+			}
+			if err != nil {
+				p.healthCheckFailed(io.Obj, source, err)
+			}
+			p.retire(io)
+			p.dropObjs(io.Obj)
+		}
+
+		p.mu.Lock()
+		refs, seq = p.uncheckedIdle(round, seq)
+		p.mu.Unlock()
+	}
+}
+
+// uncheckedIdle 从最旧的开始返回序号大于seq、这一轮还没有检查过的空闲对象，以及当前最新的序号，调用时需持有锁
+func (p *Pool) uncheckedIdle(round, seq uint64) ([]idleRef, uint64) {
+	var refs []idleRef
+	for e := p.idle.Back(); e != nil; e = e.Prev() {
+		if e.seq > seq && e.Value.checked != round {
+			refs = append(refs, idleRef{e, e.seq})
+		}
+	}
+	return refs, p.idle.seq
+}
+
+// putChecked 把检查过的io放在同一等级中检查过的对象前面，保持空闲列表的顺序。
+// checked记录每个等级中检查过的对象，其中已经被借出的会被跳过，调用时需持有锁
+func (p *Pool) putChecked(io ConnectionInfo, checked *[maxTier][]idleRef) {
+	t := clampTier(io.tier)
+	done := checked[t]
+	for len(done) > 0 && !done[len(done)-1].in(&p.idle) {
+		done = done[:len(done)-1]
+	}
+	var e *idleElem
+	if len(done) > 0 {
+		e = p.idle.InsertBefore(io, done[len(done)-1].e)
+	} else {
+		e = p.idle.PushBack(io)
+	}
+	checked[t] = append(done, idleRef{e, e.seq})
+}
+
+// healthCheckTimeout 是HealthCheck创建对象的超时时间
+const healthCheckTimeout = 3 * time.Second
+
+// HealthCheck 同步检查pool能否提供对象，可以用于readiness探针。
+// 有空闲对象时对最新的一个调用TestOnBorrow（失败时丢弃它）；没有空闲对象但还没达到MaxActive时创建一个对象放入空闲列表；
+// 否则返回ErrPoolExhausted。pool已关闭时返回ErrPoolClosed
+func (p *Pool) HealthCheck() error {
+	p.mu.Lock()
+	if p.closed {
+		err := p.err(ErrPoolClosed)
+		p.mu.Unlock()
+		return err
+	}
+	if e := p.idle.Front(); e != nil {
+		test := p.TestOnBorrow
+		if test == nil {
+			p.mu.Unlock()
+			return nil
+		}
+		io := p.idle.Remove(e)
+		p.mu.Unlock()
+
+		err := test(io.Obj)
+		p.mu.Lock()
+		if err == nil && !p.closed && p.idle.Len() < p.maxIdle() { // 检查期间MaxIdle可能变小了
+			p.idle.PushFront(io)
+			p.signal()
+			p.mu.Unlock()
+			return nil
+		}
+		if err != nil {
+			p.healthCheckFailed(io.Obj, "TestOnBorrow", err)
+		}
+		p.retire(io)
+		p.dropObjs(io.Obj)
+		return err
+	}
+
+	if max := p.maxActive(); max > 0 && p.active >= max {
+		err := p.err(ErrPoolExhausted)
+		p.mu.Unlock()
+		return err
+	}
+	if p.takeDialToken() > 0 {
+		err := p.err(ErrPoolExhausted)
+		p.mu.Unlock()
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+	return p.addIdle(ctx, false, nil) // 空闲对象已经达到MaxIdle时丢弃创建的对象，能创建对象就说明pool可用
+}
@@ -0,0 +1,195 @@
+package pool
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func waitActive(t *testing.T, p *Pool, want int) {
+	deadline := time.Now().Add(2 * time.Second)
+	for p.ActiveCount() != want && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if active := p.ActiveCount(); active != want {
+		t.Fatalf("active=%d, want %d", active, want)
+	}
+}
+
+func TestPoolKeepalive(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+	p.KeepaliveInterval = 10 * time.Millisecond
+
+	bad := make(chan interface{}, 1)
+	p.Ping = func(o interface{}) error {
+		select {
+		case b := <-bad:
+			if b == o {
+				return errors.New("ping failed")
+			}
+			bad <- b
+		default:
+		}
+		return nil
+	}
+
+	o1, _ := p.Get()
+	o2, _ := p.Get()
+	p.Put(o1)
+	p.Put(o2)
+	bad <- o1
+
+	waitActive(t, p, 1)
+	o, _ := p.Get()
+	if o != o2 {
+		t.Errorf("got %p, want %p", o, o2)
+	}
+	p.Put(o)
+	p.Close()
+	d.check("after close", p, 2, 0)
+}
+
+func TestPoolKeepaliveOneAtATime(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 3)
+	p.DropCallback = d.drop
+	defer p.Close()
+
+	objs := make([]interface{}, 3)
+	for i := range objs {
+		objs[i], _ = p.Get()
+	}
+	for _, o := range objs {
+		p.Put(o)
+	}
+	pinging := make(chan interface{})
+	unblock := make(chan struct{})
+	var pinged []interface{}
+	p.Ping = func(o interface{}) error {
+		pinged = append(pinged, o)
+		if len(pinged) == 1 {
+			pinging <- o
+			<-unblock
+		}
+		return nil
+	}
+	done := make(chan struct{})
+	go func() {
+		p.keepalive()
+		close(done)
+	}()
+	if o := <-pinging; o != objs[0] { // 从最旧的开始
+		t.Errorf("first ping %v, want %v", o, objs[0])
+	}
+	if n := p.IdleCount(); n != 2 { // 其他对象还在空闲列表中
+		t.Errorf("IdleCount()=%d during ping, want 2", n)
+	}
+	o, _ := p.Get()
+	p.Put(o)
+	close(unblock)
+	<-done
+
+	d.check("after keepalive", p, 3, 3)
+	if len(pinged) != 3 {
+		t.Errorf("pinged %d objects, want 3", len(pinged))
+	}
+	for i, info := range p.IdleConnections() { // 保持原来的顺序，最新的在前
+		if want := objs[len(objs)-1-i]; info.Obj != want {
+			t.Errorf("idle[%d]=%p, want %p", i, info.Obj, want)
+		}
+	}
+}
+
+func TestPoolHealthCheck(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+	p.HealthCheckInterval = 10 * time.Millisecond
+
+	healthy := make(chan bool, 1)
+	healthy <- true
+	p.TestOnBorrow = func(interface{}) error {
+		ok := <-healthy
+		healthy <- ok
+		if !ok {
+			return errors.New("unhealthy")
+		}
+		return nil
+	}
+
+	o1, _ := p.Get()
+	o2, _ := p.Get()
+	p.Put(o1)
+	p.Put(o2)
+	<-healthy
+	healthy <- false
+
+	waitActive(t, p, 0)
+	p.Close()
+	d.check("after close", p, 2, 0)
+}
+
+func TestPoolHealthCheckSync(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 1)
+	p.DropCallback = d.drop
+	p.MaxActive = 2
+	broken := errors.New("broken")
+	var testErr error
+	p.TestOnBorrow = func(interface{}) error { return testErr }
+
+	if err := p.HealthCheck(); err != nil { // 没有空闲对象，创建一个
+		t.Errorf("HealthCheck()=%v", err)
+	}
+	d.check("dial", p, 1, 1)
+	if n := p.IdleCount(); n != 1 {
+		t.Errorf("IdleCount()=%d, want 1", n)
+	}
+	if err := p.HealthCheck(); err != nil {
+		t.Errorf("HealthCheck()=%v", err)
+	}
+	d.check("test idle", p, 1, 1)
+
+	testErr = broken
+	if err := p.HealthCheck(); err != broken {
+		t.Errorf("HealthCheck()=%v, want %v", err, broken)
+	}
+	d.check("drop broken", p, 1, 0)
+	testErr = nil
+
+	o1, _ := p.Get()
+	o2, _ := p.Get()
+	if err := p.HealthCheck(); !errors.Is(err, ErrPoolExhausted) {
+		t.Errorf("HealthCheck()=%v, want ErrPoolExhausted", err)
+	}
+	p.Put(o1)
+	p.Put(o2)
+	p.Close()
+	if err := p.HealthCheck(); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("HealthCheck()=%v, want ErrPoolClosed", err)
+	}
+	d.check("after close", p, 3, 0)
+}
+
+func TestPoolHealthCheckMaxIdleShrunk(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 1)
+	p.DropCallback = d.drop
+	defer p.Close()
+	o, _ := p.Get()
+	p.Put(o)
+
+	p.TestOnBorrow = func(interface{}) error {
+		p.SetMaxIdle(0) // 检查期间MaxIdle变小了
+		return nil
+	}
+	if err := p.HealthCheck(); err != nil {
+		t.Errorf("HealthCheck()=%v", err)
+	}
+	if n := p.IdleCount(); n != 0 {
+		t.Errorf("IdleCount()=%d, want 0", n)
+	}
+	d.check("max idle shrunk", p, 1, 0)
+}
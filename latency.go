@@ -0,0 +1,52 @@
+package pool
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+var _ Observer = (*LatencyObserver)(nil)
+
+// LatencyObserver 是记录Get()等待时间的Observer，用蓄水池抽样保存最多sampleSize个样本，
+// 不会偏向最近或最早的样本。用SetObserver注册
+type LatencyObserver struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	seen    int64 // 一共记录过的次数
+}
+
+// NewLatencyObserver 创建最多保存sampleSize（为0时为1024）个样本的LatencyObserver
+func NewLatencyObserver(sampleSize int) *LatencyObserver {
+	if sampleSize <= 0 {
+		sampleSize = defaultLifetimeSampleSize
+	}
+	return &LatencyObserver{samples: make([]time.Duration, 0, sampleSize)}
+}
+
+func (o *LatencyObserver) OnGet(obj interface{}, reused bool, waitDuration time.Duration) {
+	o.mu.Lock()
+	o.seen++
+	if len(o.samples) < cap(o.samples) {
+		o.samples = append(o.samples, waitDuration)
+	} else if i := rand.Int63n(o.seen); i < int64(len(o.samples)) {
+		o.samples[i] = waitDuration
+	}
+	o.mu.Unlock()
+}
+
+func (o *LatencyObserver) OnPut(obj interface{}, dropped bool) {}
+func (o *LatencyObserver) OnCreate(obj interface{})            {}
+func (o *LatencyObserver) OnDestroy(obj interface{})           {}
+
+// Percentile 返回等待时间的第percentile(0-100)百分位数，没有样本时返回0
+func (o *LatencyObserver) Percentile(percentile float64) time.Duration {
+	o.mu.Lock()
+	samples := append([]time.Duration(nil), o.samples...)
+	o.mu.Unlock()
+	return percentileOf(samples, percentile)
+}
+
+func (o *LatencyObserver) P50() time.Duration { return o.Percentile(50) }
+func (o *LatencyObserver) P95() time.Duration { return o.Percentile(95) }
+func (o *LatencyObserver) P99() time.Duration { return o.Percentile(99) }
@@ -0,0 +1,52 @@
+package pool
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// LeakInfo 是LeakReport()返回的一个借出对象的信息
+type LeakInfo struct {
+	Obj        interface{}
+	BorrowedAt time.Time
+	RequestID  string // 通过GetWithRequestID借出时的请求ID
+}
+
+// LabeledGet 与GetContext相同，开启TrackActive时把label记录在借出记录中，用于LeakReport()按调用者分组
+func (p *Pool) LabeledGet(ctx context.Context, label string) (interface{}, error) {
+	return p.get(ctx, getOptions{label: label})
+}
+
+// GetWithRequestID 与GetContext相同，reqID会记录在审计日志和借出记录（需要开启TrackActive）中，
+// Put()时从借出记录中找到reqID，用于把pool的事件和应用的请求对应起来，见RequestObserver和LeakReport
+func (p *Pool) GetWithRequestID(ctx context.Context, reqID string) (interface{}, error) {
+	obj, err := p.get(ctx, getOptions{reqID: reqID})
+	if err == nil {
+		p.mu.Lock()
+		observer := p.observer
+		p.mu.Unlock()
+		observeRequest(observer, obj, reqID, false)
+	}
+	return obj, err
+}
+
+// LeakReport 按LabeledGet的label分组返回所有借出的对象，每组按借出时间排序，最早借出的在前；
+// 不是通过LabeledGet借出的对象的label为空字符串。需要开启TrackActive，否则返回nil
+func (p *Pool) LeakReport() map[string][]LeakInfo {
+	p.mu.Lock()
+	if !p.TrackActive {
+		p.mu.Unlock()
+		return nil
+	}
+	report := make(map[string][]LeakInfo)
+	for _, io := range p.borrowed {
+		report[io.label] = append(report[io.label], LeakInfo{Obj: io.Obj, BorrowedAt: io.borrowedAt, RequestID: io.reqID})
+	}
+	p.mu.Unlock()
+
+	for _, infos := range report {
+		sort.Slice(infos, func(i, j int) bool { return infos[i].BorrowedAt.Before(infos[j].BorrowedAt) })
+	}
+	return report
+}
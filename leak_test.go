@@ -0,0 +1,50 @@
+package pool
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPoolLeakReport(t *testing.T) {
+	now := time.Now()
+	nowFunc = func() time.Time {
+		return now
+	}
+	defer func() {
+		nowFunc = time.Now
+	}()
+
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 3)
+	defer p.Close()
+	if _, err := p.LabeledGet(context.Background(), "handler"); err != nil {
+		t.Fatal(err)
+	}
+	if r := p.LeakReport(); r != nil {
+		t.Errorf("LeakReport()=%v without TrackActive, want nil", r)
+	}
+
+	p.TrackActive = true
+	o1, _ := p.LabeledGet(context.Background(), "handler")
+	now = now.Add(time.Second)
+	o2, _ := p.LabeledGet(context.Background(), "handler")
+	o3, _ := p.Get()
+
+	r := p.LeakReport()
+	if h := r["handler"]; len(h) != 2 || h[0].Obj != o1 || h[1].Obj != o2 {
+		t.Errorf("LeakReport()[handler]=%v, want [%v %v]", h, o1, o2)
+	}
+	if u := r[""]; len(u) != 1 || u[0].Obj != o3 {
+		t.Errorf("LeakReport()[\"\"]=%v, want [%v]", u, o3)
+	}
+
+	p.Put(o1)
+	p.Put(o2)
+	o, _ := p.Get() // 放回后label被清除
+	if h := p.LeakReport()["handler"]; len(h) != 0 {
+		t.Errorf("LeakReport()[handler]=%v after Put, want empty", h)
+	}
+	p.Put(o)
+	p.Put(o3)
+}
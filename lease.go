@@ -0,0 +1,87 @@
+package pool
+
+import (
+	"log/slog"
+	"time"
+)
+
+// LeasedConn 是Lease借出的对象，租期到了之后会被自动放回pool
+type LeasedConn struct {
+	p        *Pool
+	obj      interface{}
+	deadline time.Time
+	timer    *time.Timer
+}
+
+// Lease 借出一个对象，duration之后自动调用Put放回，并通过SetLogger设置的logger或Logger输出警告。
+// 在这之前调用Cancel()或者直接Put/PutErr这个对象都会取消自动放回。对象需要能作为map的key
+func (p *Pool) Lease(duration time.Duration) (*LeasedConn, error) {
+	obj, err := p.Get()
+	if err != nil {
+		return nil, err
+	}
+	l := &LeasedConn{p: p, obj: obj, deadline: nowFunc().Add(duration)}
+	p.mu.Lock()
+	if p.leases == nil {
+		p.leases = make(map[interface{}]*LeasedConn)
+	}
+	p.leases[obj] = l
+	l.timer = time.AfterFunc(duration, l.expire)
+	p.mu.Unlock()
+	return l, nil
+}
+
+// Value 返回借出的对象
+func (l *LeasedConn) Value() interface{} {
+	return l.obj
+}
+
+// Cancel 提前把对象放回pool，对象已经被放回时不做任何事
+func (l *LeasedConn) Cancel() {
+	if l.take() {
+		l.p.Put(l.obj)
+	}
+}
+
+// Remaining 返回租期还剩多久，已经到期时返回0
+func (l *LeasedConn) Remaining() time.Duration {
+	if d := l.deadline.Sub(nowFunc()); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// expire 在租期到了之后放回对象
+func (l *LeasedConn) expire() {
+	if !l.take() {
+		return
+	}
+	l.p.mu.Lock()
+	l.p.log(slog.LevelWarn, "pool: lease expired", "object", l.obj)
+	logs := l.p.takeLogs()
+	l.p.mu.Unlock()
+	emitLogs(logs)
+	l.p.Put(l.obj)
+}
+
+// take 结束租期，返回这次调用是否真正结束了租期
+func (l *LeasedConn) take() bool {
+	l.p.mu.Lock()
+	defer l.p.mu.Unlock()
+	if l.p.leases[l.obj] != l {
+		return false
+	}
+	l.p.endLease(l.obj)
+	return true
+}
+
+// endLease 放回对象时取消它的租期，调用时需持有锁
+func (p *Pool) endLease(obj interface{}) {
+	if len(p.leases) == 0 { // 避免对象不能作为map的key时panic
+		return
+	}
+	if l, ok := p.leases[obj]; ok {
+		l.timer.Stop()
+		delete(p.leases, obj)
+	}
+}
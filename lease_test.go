@@ -0,0 +1,56 @@
+package pool
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPoolLease(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	log := &lockedBuffer{}
+	p.SetLogger(slog.New(slog.NewTextHandler(log, nil)))
+	defer p.Close()
+
+	l, err := p.Lease(10 * time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := l.Remaining(); r <= 0 || r > 10*time.Millisecond {
+		t.Errorf("Remaining()=%v", r)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for p.IdleCount() != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := p.IdleCount(); n != 1 {
+		t.Fatalf("IdleCount()=%d after lease expired, want 1", n)
+	}
+	if !strings.Contains(log.String(), `level=WARN msg="pool: lease expired"`) {
+		t.Errorf("log=%q, want expired warning", log.String())
+	}
+	if r := l.Remaining(); r != 0 {
+		t.Errorf("Remaining()=%v after expiry, want 0", r)
+	}
+	l.Cancel() // 已经放回了
+	if n := p.IdleCount(); n != 1 {
+		t.Errorf("IdleCount()=%d, want 1", n)
+	}
+
+	l1, _ := p.Lease(time.Hour)
+	l2, _ := p.Lease(time.Hour)
+	l1.Cancel()
+	p.Put(l2.Value()) // 直接放回也会取消租期
+	if n := p.IdleCount(); n != 2 {
+		t.Errorf("IdleCount()=%d, want 2", n)
+	}
+	p.mu.Lock()
+	leases := len(p.leases)
+	p.mu.Unlock()
+	if leases != 0 {
+		t.Errorf("%d leases left", leases)
+	}
+	d.check("leases", p, 2, 2)
+}
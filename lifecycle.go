@@ -0,0 +1,40 @@
+package pool
+
+import "context"
+
+// NewPoolContext 用newFunc和opts创建Pool，ctx被取消时会调用Shutdown关闭pool，后台goroutine也随之退出。
+// 直接关闭pool时监听ctx的goroutine也会退出
+func NewPoolContext(ctx context.Context, newFunc func() (interface{}, error), opts ...Option) *Pool {
+	p := &Pool{New: newFunc, ctx: ctx, closeCh: make(chan struct{})}
+	for _, opt := range opts {
+		opt(p)
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+			p.Shutdown(context.Background())
+		case <-p.closeCh:
+		}
+	}()
+	return p
+}
+
+// Shutdown 关闭pool，然后等待所有借出的对象被放回（放回时会被丢弃），
+// ctx被取消时返回ctx.Err()。Reset之前借出的有记录的对象不需要等待
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.Close()
+
+	p.mu.Lock()
+	for p.active > 0 {
+		ch := p.idleChanged() // 不进入Get的等待队列
+		p.mu.Unlock()
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		p.mu.Lock()
+	}
+	p.mu.Unlock()
+	return nil
+}
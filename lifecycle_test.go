@@ -0,0 +1,77 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestNewPoolContext(t *testing.T) {
+	d := &poolDialer{t: t}
+	dropped := make(chan interface{}, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	p := NewPoolContext(ctx, d.dial, WithMaxIdle(2), WithMaxActive(2))
+	p.DropCallback = func(o interface{}) { dropped <- o }
+
+	o1, _ := p.Get()
+	o2, _ := p.Get()
+	p.Put(o2)
+
+	cancel()
+	if o := <-dropped; o != o2 {
+		t.Errorf("dropped %v, want idle %v", o, o2)
+	}
+	if _, err := p.Get(); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("Get() after cancel: err=%v, want ErrPoolClosed", err)
+	}
+	p.Put(o1)
+	if o := <-dropped; o != o1 {
+		t.Errorf("dropped %v, want %v", o, o1)
+	}
+	waitActive(t, p, 0)
+}
+
+func TestNewPoolContextClose(t *testing.T) {
+	d := &poolDialer{t: t}
+	before := runtime.NumGoroutine()
+	p := NewPoolContext(context.Background(), d.dial)
+	p.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("goroutines=%d after Close, want %d", n, before)
+	}
+}
+
+func TestPoolShutdown(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+
+	o, _ := p.Get()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := p.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Shutdown() with borrowed object: err=%v, want DeadlineExceeded", err)
+	}
+
+	done := make(chan error)
+	go func() { done <- p.Shutdown(context.Background()) }()
+	select {
+	case err := <-done:
+		t.Fatalf("Shutdown() returned %v before object was put back", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	if n := p.WaiterCount(); n != 0 { // Shutdown不在Get的等待队列中
+		t.Errorf("WaiterCount()=%d, want 0", n)
+	}
+	p.Put(o)
+	if err := <-done; err != nil {
+		t.Errorf("Shutdown()=%v", err)
+	}
+	d.check("after shutdown", p, 1, 0)
+}
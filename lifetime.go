@@ -0,0 +1,72 @@
+package pool
+
+import (
+	"sort"
+	"time"
+)
+
+// defaultLifetimeSampleSize 是LifetimeSampleSize为0时保存的存活时间数
+const defaultLifetimeSampleSize = 1024
+
+// retire 与release相同，同时记录io的存活时间，调用时需持有锁
+func (p *Pool) retire(io ConnectionInfo) {
+	p.recordLifetime(io.CreatedAt)
+	p.release()
+}
+
+// recordLifetime 记录一个从created开始存活的对象被丢弃了，created为零值时不记录，调用时需持有锁
+func (p *Pool) recordLifetime(created time.Time) {
+	if created.IsZero() {
+		return
+	}
+	size := p.LifetimeSampleSize
+	if size <= 0 {
+		size = defaultLifetimeSampleSize
+	}
+	if len(p.lifetimes) != size {
+		p.lifetimes, p.lifeNext, p.lifeCount = make([]time.Duration, size), 0, 0
+	}
+	p.lifetimes[p.lifeNext] = nowFunc().Sub(created)
+	p.lifeNext = (p.lifeNext + 1) % size
+	if p.lifeCount < size {
+		p.lifeCount++
+	}
+}
+
+// LifetimePercentile 返回最近被丢弃的对象从创建到丢弃的时间的第percentile(0-100)百分位数，没有记录时返回0，
+// 用于调整IdleTimeout等参数。借出后丢弃的对象只在记录了借出对象（如开启TrackActive）时才会被统计
+func (p *Pool) LifetimePercentile(percentile float64) time.Duration {
+	p.mu.Lock()
+	samples := append([]time.Duration(nil), p.lifetimes[:p.lifeCount]...)
+	p.mu.Unlock()
+	return percentileOf(samples, percentile)
+}
+
+// percentileOf 对samples排序并返回第percentile(0-100)百分位数，samples为空时返回0
+func percentileOf(samples []time.Duration, percentile float64) time.Duration {
+	if len(samples) == 0 {
+		return 0
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	i := int(percentile / 100 * float64(len(samples)-1))
+	if i < 0 {
+		i = 0
+	} else if i >= len(samples) {
+		i = len(samples) - 1
+	}
+	return samples[i]
+}
+
+// AverageLifetime 返回最近被丢弃的对象的平均存活时间，没有记录时返回0
+func (p *Pool) AverageLifetime() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.lifeCount == 0 {
+		return 0
+	}
+	var sum time.Duration
+	for _, d := range p.lifetimes[:p.lifeCount] {
+		sum += d
+	}
+	return sum / time.Duration(p.lifeCount)
+}
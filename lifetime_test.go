@@ -0,0 +1,44 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPoolLifetimePercentile(t *testing.T) {
+	now := time.Now()
+	nowFunc = func() time.Time {
+		return now
+	}
+	defer func() {
+		nowFunc = time.Now
+	}()
+
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 3)
+	p.TrackActive = true
+	defer p.Close()
+
+	if got := p.LifetimePercentile(50); got != 0 {
+		t.Errorf("LifetimePercentile(50)=%v without samples, want 0", got)
+	}
+	var objs []interface{}
+	for i := 0; i < 3; i++ {
+		o, _ := p.Get()
+		objs = append(objs, o)
+		now = now.Add(time.Second)
+	}
+	for _, o := range objs {
+		p.Put(o)
+	}
+	p.Drain() // 存活了3s、2s、1s
+
+	for percentile, want := range map[float64]time.Duration{0: time.Second, 50: 2 * time.Second, 100: 3 * time.Second} {
+		if got := p.LifetimePercentile(percentile); got != want {
+			t.Errorf("LifetimePercentile(%v)=%v, want %v", percentile, got, want)
+		}
+	}
+	if got := p.AverageLifetime(); got != 2*time.Second {
+		t.Errorf("AverageLifetime()=%v, want 2s", got)
+	}
+}
@@ -0,0 +1,44 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPoolMaxIdleTime(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+	p.MaxIdleTime = time.Second
+
+	now := time.Now()
+	nowFunc = func() time.Time {
+		return now
+	}
+	defer func() {
+		nowFunc = time.Now
+	}()
+
+	o, _ := p.Get()
+	p.Put(o)
+	now = now.Add(time.Second)
+
+	// 移出空闲列表的对象仍然可以被借出
+	o2, err := p.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o2 != o {
+		t.Errorf("Get()=%v, want %v", o2, o)
+	}
+	d.check("borrow from limbo", p, 1, 1)
+
+	p.Put(o2) // 放回时被丢弃
+	d.check("put limbo object", p, 1, 0)
+	if n := p.IdleCount(); n != 0 {
+		t.Errorf("IdleCount()=%d, want 0", n)
+	}
+
+	p.Close()
+	d.check("after close", p, 1, 0)
+}
@@ -0,0 +1,90 @@
+package pool
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SetLogger 设置输出结构化日志的logger，为nil时使用Logger字段，都没有设置时不输出。创建对象和淘汰空闲对象为Debug级别，
+// janitor清理的结果和自动调整MaxActive为Info级别，对象耗尽、健康检查失败和Lease到期为Warn级别。日志都在释放锁之后输出
+func (p *Pool) SetLogger(l *slog.Logger) {
+	p.mu.Lock()
+	p.logger = l
+	p.mu.Unlock()
+}
+
+// logWith 返回带有pool名字的logger，没有调用SetLogger时把Logger包装为文本格式的logger，
+// 都没有设置时返回nil，调用时需持有锁
+func (p *Pool) logWith() *slog.Logger {
+	l := p.logger
+	if l == nil {
+		if p.Logger == nil {
+			return nil
+		}
+		l = slog.New(slog.NewTextHandler(p.Logger, nil))
+	}
+	if p.Name == "" {
+		return l
+	}
+	return l.With("pool", p.Name)
+}
+
+// logRecord 是持有锁时记录、释放锁之后才输出的日志
+type logRecord struct {
+	logger *slog.Logger
+	level  slog.Level
+	msg    string
+	args   []interface{}
+}
+
+// log 记录一条带有pool名字和当前对象数的日志，调用时需持有锁。
+// 日志由释放锁的地方取出（takeLogs）并在释放锁之后输出（emitLogs），如dropObjs和waitTurn
+func (p *Pool) log(level slog.Level, msg string, args ...interface{}) {
+	l := p.logWith()
+	if l == nil {
+		return
+	}
+	args = append(args, "active", p.active, "idle", p.idle.Len())
+	p.logs = append(p.logs, logRecord{logger: l, level: level, msg: msg, args: args})
+}
+
+// takeLogs 取出持有锁时记录的日志，调用时需持有锁
+func (p *Pool) takeLogs() []logRecord {
+	logs := p.logs
+	p.logs = nil
+	return logs
+}
+
+// emitLogs 输出takeLogs取出的日志，调用时不能持有锁
+func emitLogs(logs []logRecord) {
+	for _, r := range logs {
+		r.logger.Log(context.Background(), r.level, r.msg, r.args...)
+	}
+}
+
+// evict 记录一个被淘汰的空闲对象，reason是淘汰的原因，调用时需持有锁
+func (p *Pool) evict(obj interface{}, reason string) {
+	p.stats.evictions.Add(1)
+	p.event(EventEvicted, obj, nil)
+	p.log(slog.LevelDebug, "pool: object evicted", "reason", reason)
+}
+
+// recycle 记录一个因为超出MaxIdle或过期被淘汰的空闲对象，dropObjs会调用Recycle代替DropCallback，
+// 没有设置Recycle或对象的版本已经过时时不记录。Drain、Compact等其他淘汰直接丢弃对象，调用时需持有锁
+func (p *Pool) recycle(io ConnectionInfo) {
+	if p.Recycle == nil || io.version < p.ConnectionVersion {
+		return
+	}
+	if p.recycling == nil {
+		p.recycling = make(map[interface{}]ConnectionInfo)
+	}
+	p.recycling[io.Obj] = io
+}
+
+// healthCheckFailed 记录一个没有通过TestOnBorrow或Ping的对象，source是检查的名字，调用时需持有锁
+func (p *Pool) healthCheckFailed(obj interface{}, source string, err error) {
+	p.event(EventHealthCheckFailed, obj, err)
+	p.recordError(err, source)
+	p.healthFailures++
+	p.log(slog.LevelWarn, "pool: health check failed", "source", source, "error", err)
+}
@@ -0,0 +1,58 @@
+package pool
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// lockCheckWriter 在写入时获取pool的锁，日志在持有锁时输出会死锁
+type lockCheckWriter struct {
+	p   *Pool
+	buf bytes.Buffer
+}
+
+func (w *lockCheckWriter) Write(b []byte) (int, error) {
+	w.p.ActiveCount()
+	return w.buf.Write(b)
+}
+
+func TestPoolSetLogger(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 1)
+	p.Name = "db"
+	p.MaxActive = 1
+	w := &lockCheckWriter{p: p}
+	p.SetLogger(slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer p.Close()
+
+	o, _ := p.Get()
+	p.Get() // 耗尽
+	p.Put(o)
+	p.Drain()
+	p.TestOnBorrow = func(interface{}) error { return errors.New("broken") }
+	o, _ = p.Get()
+	p.Put(o)
+	p.Get()
+
+	out := w.buf.String()
+	for _, want := range []string{
+		`level=DEBUG msg="pool: object created" pool=db`,
+		`level=WARN msg="pool: exhausted" pool=db max_active=1 waiters=0 active=1 idle=0`,
+		`level=DEBUG msg="pool: object evicted" pool=db reason=drained`,
+		`level=WARN msg="pool: health check failed" pool=db source=TestOnBorrow error=broken`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log output does not contain %q:\n%s", want, out)
+		}
+	}
+
+	w.buf.Reset()
+	p.SetLogger(nil)
+	p.Drain()
+	if w.buf.Len() != 0 {
+		t.Errorf("logged after SetLogger(nil): %s", w.buf.String())
+	}
+}
@@ -0,0 +1,51 @@
+package pool
+
+import "sync"
+
+// ReduceMaxActive 临时把MaxActive降到tempMax（至少为1），返回的函数用于恢复原来的上限，一般这样使用：
+//
+//	defer p.ReduceMaxActive(2)()
+//
+// 已经借出的对象不会被回收，只是active不小于tempMax时不再创建新的对象。
+// 同时有多个ReduceMaxActive时生效的是最小的那个，返回的函数可以按任意顺序调用，多次调用只有第一次有效
+func (p *Pool) ReduceMaxActive(tempMax int) func() {
+	if tempMax < 1 {
+		tempMax = 1
+	}
+	p.mu.Lock()
+	if p.activeCaps == nil {
+		p.activeCaps = make(map[uint64]int)
+	}
+	p.capSeq++
+	id := p.capSeq
+	p.activeCaps[id] = tempMax
+	p.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			p.mu.Lock()
+			delete(p.activeCaps, id)
+			p.broadcast() // 上限变大，等待者需要重新检查
+			p.mu.Unlock()
+		})
+	}
+}
+
+// Throttle 把有效的MaxActive临时降到MaxActive*percent/100（至少为1），用于故障处理时减少对下游的压力而不用修改配置。
+// percent为100时取消限制；为0时暂停所有新的借出，Get()会阻塞(Wait为true时)或返回ErrPoolExhausted，但不关闭pool。
+// 没有设置MaxActive时只有percent为0才生效
+func (p *Pool) Throttle(percent int) {
+	if percent < 0 {
+		percent = 0
+	} else if percent > 100 {
+		percent = 100
+	}
+	p.mu.Lock()
+	old := p.throttle
+	p.throttle = 100 - percent
+	if p.throttle < old {
+		p.broadcast() // 上限变大，等待者需要重新检查
+	}
+	p.mu.Unlock()
+}
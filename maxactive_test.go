@@ -0,0 +1,79 @@
+package pool
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPoolReduceMaxActive(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 4)
+	p.MaxActive = 4
+	defer p.Close()
+
+	o1, _ := p.Get()
+	restore1 := p.ReduceMaxActive(3)
+	restore2 := p.ReduceMaxActive(2)
+	o2, _ := p.Get()
+	if _, err := p.Get(); !errors.Is(err, ErrPoolExhausted) {
+		t.Errorf("Get() with tempMax 2: %v, want ErrPoolExhausted", err)
+	}
+
+	restore2()
+	restore2() // 多次调用无影响
+	o3, err := p.Get()
+	if err != nil {
+		t.Errorf("Get() with tempMax 3: %v", err)
+	}
+	if _, err := p.Get(); !errors.Is(err, ErrPoolExhausted) {
+		t.Errorf("Get() with tempMax 3: %v, want ErrPoolExhausted", err)
+	}
+
+	restore1()
+	o4, err := p.Get()
+	if err != nil {
+		t.Errorf("Get() after restore: %v", err)
+	}
+	for _, o := range []interface{}{o1, o2, o3, o4} {
+		p.Put(o)
+	}
+	d.check("reduce", p, 4, 4)
+}
+
+func TestPoolThrottle(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 4)
+	p.MaxActive = 4
+	defer p.Close()
+
+	p.Throttle(50)
+	o1, _ := p.Get()
+	o2, _ := p.Get()
+	if _, err := p.Get(); !errors.Is(err, ErrPoolExhausted) {
+		t.Errorf("Get() with 50%%: %v, want ErrPoolExhausted", err)
+	}
+	p.Put(o2)
+
+	p.Throttle(0) // 空闲对象也不能借出
+	if _, err := p.Get(); !errors.Is(err, ErrPoolExhausted) {
+		t.Errorf("Get() with 0%%: %v, want ErrPoolExhausted", err)
+	}
+
+	p.Wait = true
+	got := make(chan interface{})
+	go func() {
+		o, _ := p.Get()
+		got <- o
+	}()
+	waitWaiters(t, p, 1)
+	p.Throttle(100)
+	select {
+	case o := <-got:
+		p.Put(o)
+	case <-time.After(time.Second):
+		t.Fatal("waiter was not woken after Throttle(100)")
+	}
+	p.Put(o1)
+	d.check("throttle", p, 2, 2)
+}
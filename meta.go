@@ -0,0 +1,32 @@
+package pool
+
+// SetMeta 给对象附加一个元数据，value为nil时删除key。对象被丢弃时它的元数据会被清除
+func (p *Pool) SetMeta(obj interface{}, key string, value interface{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if value == nil {
+		if m := p.meta[obj]; m != nil {
+			delete(m, key)
+			if len(m) == 0 {
+				delete(p.meta, obj)
+			}
+		}
+		return
+	}
+	if p.meta == nil {
+		p.meta = make(map[interface{}]map[string]interface{})
+	}
+	m := p.meta[obj]
+	if m == nil {
+		m = make(map[string]interface{})
+		p.meta[obj] = m
+	}
+	m[key] = value
+}
+
+// GetMeta 返回SetMeta附加到对象上的元数据，没有时返回nil
+func (p *Pool) GetMeta(obj interface{}, key string) interface{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.meta[obj][key]
+}
@@ -0,0 +1,30 @@
+package pool
+
+import "testing"
+
+func TestPoolMeta(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 1)
+	p.DropCallback = d.drop
+	defer p.Close()
+
+	o, _ := p.Get()
+	if v := p.GetMeta(o, "token"); v != nil {
+		t.Errorf("GetMeta() before SetMeta=%v, want nil", v)
+	}
+	p.SetMeta(o, "token", "abc")
+	p.SetMeta(o, "errors", 1)
+	p.Put(o)
+	if v := p.GetMeta(o, "token"); v != "abc" {
+		t.Errorf("GetMeta(token)=%v, want abc", v)
+	}
+	p.SetMeta(o, "errors", nil)
+	if v := p.GetMeta(o, "errors"); v != nil {
+		t.Errorf("GetMeta(errors) after delete=%v, want nil", v)
+	}
+
+	p.Drain() // 丢弃时清除元数据
+	if v := p.GetMeta(o, "token"); v != nil {
+		t.Errorf("GetMeta() after drop=%v, want nil", v)
+	}
+}
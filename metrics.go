@@ -0,0 +1,67 @@
+package pool
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Metrics 按名字返回pool的所有数值指标，可以直接交给StatsD等接受map[string]float64的监控系统
+func (p *Pool) Metrics() map[string]float64 {
+	s := p.Snapshot()
+	return map[string]float64{
+		"pool.active":          float64(s.Active),
+		"pool.idle":            float64(s.Idle),
+		"pool.waiting":         float64(s.Waiting),
+		"pool.max_active":      float64(s.MaxActive),
+		"pool.max_idle":        float64(s.MaxIdle),
+		"pool.hits_total":      float64(s.Stats.Hits),
+		"pool.misses_total":    float64(s.Stats.Misses),
+		"pool.errors_total":    float64(s.Stats.Errors),
+		"pool.evictions_total": float64(s.Stats.Evictions),
+	}
+}
+
+// WriteMetrics 按format把Metrics()写到w，format可以是"statsd"或"prometheus"。
+// prometheus格式中名字里的"."会被替换成"_"，设置了Name时会带上pool标签
+func (p *Pool) WriteMetrics(w io.Writer, format string) error {
+	metrics := p.Metrics()
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	switch format {
+	case "statsd":
+		for _, name := range names {
+			fmt.Fprintf(&b, "%s:%s|g\n", name, formatMetric(metrics[name]))
+		}
+	case "prometheus":
+		p.mu.Lock()
+		var labels string
+		if p.Name != "" {
+			labels = fmt.Sprintf("{pool=%q}", p.Name)
+		}
+		p.mu.Unlock()
+		for _, name := range names {
+			promName := strings.ReplaceAll(name, ".", "_")
+			typ := "gauge"
+			if strings.HasSuffix(name, "_total") {
+				typ = "counter"
+			}
+			fmt.Fprintf(&b, "# TYPE %s %s\n%s%s %s\n", promName, typ, promName, labels, formatMetric(metrics[name]))
+		}
+	default:
+		return fmt.Errorf("pool: unknown metrics format %q", format)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func formatMetric(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
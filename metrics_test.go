@@ -0,0 +1,48 @@
+package pool
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPoolMetrics(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.Name = "db"
+	p.MaxActive = 4
+	defer p.Close()
+
+	o1, _ := p.Get()
+	o2, _ := p.Get()
+	p.Put(o1)
+	defer p.Put(o2)
+
+	m := p.Metrics()
+	if m["pool.active"] != 2 || m["pool.idle"] != 1 || m["pool.max_active"] != 4 || m["pool.misses_total"] != 2 {
+		t.Errorf("unexpected metrics %v", m)
+	}
+	if len(m) != 9 {
+		t.Errorf("len(Metrics())=%d, want 9", len(m))
+	}
+
+	var b strings.Builder
+	if err := p.WriteMetrics(&b, "statsd"); err != nil {
+		t.Fatal(err)
+	}
+	if s := b.String(); !strings.HasPrefix(s, "pool.active:2|g\npool.errors_total:0|g\n") {
+		t.Errorf("statsd output:\n%s", s)
+	}
+
+	b.Reset()
+	if err := p.WriteMetrics(&b, "prometheus"); err != nil {
+		t.Fatal(err)
+	}
+	if s := b.String(); !strings.Contains(s, "# TYPE pool_misses_total counter\npool_misses_total{pool=\"db\"} 2\n") ||
+		!strings.Contains(s, "# TYPE pool_idle gauge\npool_idle{pool=\"db\"} 1\n") {
+		t.Errorf("prometheus output:\n%s", s)
+	}
+
+	if err := p.WriteMetrics(&b, "xml"); err == nil {
+		t.Error("WriteMetrics(xml) should fail")
+	}
+}
@@ -0,0 +1,106 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// MirroredPool 优先从Primary获取对象，Primary返回ErrPoolExhausted或ErrPoolClosed时从Fallback获取。
+// 按对象的地址记录它是从哪个pool借出的，所以对象必须是指针、map或channel，
+// 其他类型的对象会被丢弃，Get()返回Code为ErrCodeInvalidConfig的错误
+type MirroredPool struct {
+	Primary  *Pool
+	Fallback *Pool
+
+	mu     sync.Mutex
+	owners map[unsafe.Pointer]*Pool // 对象的地址 -> 借出它的pool
+}
+
+func NewMirroredPool(primary, fallback *Pool) *MirroredPool {
+	return &MirroredPool{Primary: primary, Fallback: fallback}
+}
+
+func (m *MirroredPool) Get() (interface{}, error) {
+	return m.GetContext(context.Background())
+}
+
+func (m *MirroredPool) GetContext(ctx context.Context) (interface{}, error) {
+	p := m.Primary
+	obj, err := p.GetContext(ctx)
+	if errors.Is(err, ErrPoolExhausted) || errors.Is(err, ErrPoolClosed) {
+		p = m.Fallback
+		obj, err = p.GetContext(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	key, ok := identity(obj)
+	if !ok {
+		p.PutErr(obj, errMirrorNotPointer)
+		return nil, errMirrorNotPointer
+	}
+	m.mu.Lock()
+	if m.owners == nil {
+		m.owners = make(map[unsafe.Pointer]*Pool)
+	}
+	m.owners[key] = p
+	m.mu.Unlock()
+	return obj, nil
+}
+
+// Put 把对象放回借出它的pool
+func (m *MirroredPool) Put(obj interface{}) {
+	m.owner(obj).Put(obj)
+}
+
+func (m *MirroredPool) PutErr(obj interface{}, err error) {
+	m.owner(obj).PutErr(obj, err)
+}
+
+// owner 返回借出obj的pool并删除记录，没有记录时返回Primary
+func (m *MirroredPool) owner(obj interface{}) *Pool {
+	key, ok := identity(obj)
+	if !ok {
+		return m.Primary
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.owners[key]
+	if !ok {
+		return m.Primary
+	}
+	delete(m.owners, key)
+	return p
+}
+
+// identity 返回obj的地址，obj不是非nil的指针、map或channel时返回false。
+// 不同的对象地址不同，值相等的对象也不会冲突
+func identity(obj interface{}) (unsafe.Pointer, bool) {
+	v := reflect.ValueOf(obj)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Chan, reflect.UnsafePointer:
+		if !v.IsNil() {
+			return v.UnsafePointer(), true
+		}
+	}
+	return nil, false
+}
+
+// Close 关闭Primary和Fallback
+func (m *MirroredPool) Close() {
+	m.Primary.Close()
+	m.Fallback.Close()
+}
+
+func (m *MirroredPool) OnceClose() {
+	m.Primary.OnceClose()
+	m.Fallback.OnceClose()
+}
+
+// ActiveCount 返回两个pool的活跃对象总数
+func (m *MirroredPool) ActiveCount() int {
+	return m.Primary.ActiveCount() + m.Fallback.ActiveCount()
+}
@@ -0,0 +1,66 @@
+package pool
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMirroredPool(t *testing.T) {
+	d1, d2 := &poolDialer{t: t}, &poolDialer{t: t}
+	primary := NewPool(d1.dial, 1)
+	primary.DropCallback = d1.drop
+	primary.MaxActive = 1
+	fallback := NewPool(d2.dial, 1)
+	fallback.DropCallback = d2.drop
+	m := NewMirroredPool(primary, fallback)
+
+	o1, _ := m.Get()
+	o2, err := m.Get() // primary已满
+	if err != nil {
+		t.Fatal(err)
+	}
+	d1.check("primary", primary, 1, 1)
+	d2.check("fallback", fallback, 1, 1)
+	if n := m.ActiveCount(); n != 2 {
+		t.Errorf("ActiveCount()=%d, want 2", n)
+	}
+
+	m.Put(o2)
+	m.Put(o1)
+	if primary.IdleCount() != 1 || fallback.IdleCount() != 1 {
+		t.Errorf("idle=%d,%d, want 1,1", primary.IdleCount(), fallback.IdleCount())
+	}
+
+	primary.Close()
+	if o, _ := m.Get(); o != o2 { // primary关闭后使用fallback
+		t.Errorf("Get()=%v, want %v", o, o2)
+	}
+	m.Put(o2)
+	m.Close()
+	d1.check("primary closed", primary, 1, 0)
+	d2.check("fallback closed", fallback, 1, 0)
+}
+
+func TestMirroredPoolIdentity(t *testing.T) {
+	primary := NewPool(func() (interface{}, error) { return &struct{ n int }{}, nil }, 1)
+	primary.MaxActive = 1
+	fallback := NewPool(func() (interface{}, error) { return &struct{ n int }{}, nil }, 1)
+	m := NewMirroredPool(primary, fallback)
+	defer m.Close()
+
+	o1, _ := m.Get()
+	o2, _ := m.Get() // 与o1的值相等，但来自fallback
+	m.Put(o2)
+	m.Put(o1)
+	if primary.IdleCount() != 1 || fallback.IdleCount() != 1 {
+		t.Errorf("idle=%d,%d, want 1,1", primary.IdleCount(), fallback.IdleCount())
+	}
+
+	values := NewMirroredPool(NewPool(func() (interface{}, error) { return []int{1}, nil }, 1), fallback)
+	if _, err := values.Get(); !errors.Is(err, &PoolError{Code: ErrCodeInvalidConfig}) {
+		t.Errorf("Get() of a slice: err=%v, want ErrCodeInvalidConfig", err)
+	}
+	if n := values.Primary.ActiveCount(); n != 0 {
+		t.Errorf("ActiveCount()=%d, want 0", n)
+	}
+}
@@ -0,0 +1,64 @@
+// Package mock 提供用于单元测试的pool.PoolIface实现
+package mock
+
+import (
+	"sync"
+
+	"github.com/chen-zyc/pool"
+)
+
+var _ pool.PoolIface = (*MockPool)(nil)
+
+type MockPool struct {
+	ReturnValues     []interface{} // Get()依次返回的对象，用完后从头开始
+	ErrorOnGetAfterN int           // 成功Get() N次后返回Err，为0时不返回错误
+	Err              error         // 为nil时返回pool.ErrPoolExhausted
+	RecordedPuts     []interface{} // 记录所有Put()的对象
+
+	mu     sync.Mutex
+	gets   int
+	closed bool
+}
+
+func (m *MockPool) Get() (interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil, pool.ErrPoolClosed
+	}
+	if m.ErrorOnGetAfterN > 0 && m.gets >= m.ErrorOnGetAfterN {
+		if m.Err != nil {
+			return nil, m.Err
+		}
+		return nil, pool.ErrPoolExhausted
+	}
+	var obj interface{}
+	if n := len(m.ReturnValues); n > 0 {
+		obj = m.ReturnValues[m.gets%n]
+	}
+	m.gets++
+	return obj, nil
+}
+
+func (m *MockPool) Put(obj interface{}) {
+	m.mu.Lock()
+	m.RecordedPuts = append(m.RecordedPuts, obj)
+	m.mu.Unlock()
+}
+
+func (m *MockPool) Close() {
+	m.mu.Lock()
+	m.closed = true
+	m.mu.Unlock()
+}
+
+func (m *MockPool) OnceClose() {
+	m.Close()
+}
+
+// ActiveCount 返回Get()成功的次数减去Put()的次数
+func (m *MockPool) ActiveCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.gets - len(m.RecordedPuts)
+}
@@ -0,0 +1,40 @@
+package mock
+
+import (
+	"testing"
+
+	"github.com/chen-zyc/pool"
+)
+
+func TestMockPool(t *testing.T) {
+	m := &MockPool{
+		ReturnValues:     []interface{}{1, 2},
+		ErrorOnGetAfterN: 3,
+	}
+
+	for i, want := range []interface{}{1, 2, 1} {
+		o, err := m.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if o != want {
+			t.Errorf("get %d: got %v, want %v", i, o, want)
+		}
+	}
+	if _, err := m.Get(); err != pool.ErrPoolExhausted {
+		t.Errorf("err=%v, want %v", err, pool.ErrPoolExhausted)
+	}
+
+	m.Put(1)
+	if n := m.ActiveCount(); n != 2 {
+		t.Errorf("active=%d, want 2", n)
+	}
+	if len(m.RecordedPuts) != 1 || m.RecordedPuts[0] != 1 {
+		t.Errorf("puts=%v, want [1]", m.RecordedPuts)
+	}
+
+	m.Close()
+	if _, err := m.Get(); err != pool.ErrPoolClosed {
+		t.Errorf("err=%v, want %v", err, pool.ErrPoolClosed)
+	}
+}
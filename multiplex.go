@@ -0,0 +1,83 @@
+package pool
+
+import "errors"
+
+var errSharedBroken = errors.New("pool: shared object was returned with an error")
+
+// sharedConn 记录设置了MaxConcurrentUses时被同时借出的对象
+type sharedConn struct {
+	refs     int  // 正在使用的借用者数量
+	broken   bool // 有借用者通过PutErr放回了错误，不再借出，最后一个借用者放回时丢弃
+	readOnly bool // 因为ShareReadOnly共享的对象，ActiveCount()按借用者计数
+}
+
+// maxUses 返回一个对象最多可以同时被借出多少次，调用时需持有锁
+func (p *Pool) maxUses() int {
+	max := p.MaxConcurrentUses
+	if p.MaxPipelineDepth > max {
+		max = p.MaxPipelineDepth
+	}
+	if p.ShareReadOnly && p.IsReadOnly != nil && max <= 1 {
+		max = int(^uint(0) >> 1) // 只读对象不限制借用者数量
+	}
+	return max
+}
+
+// borrowShared 借出一个还没有达到MaxConcurrentUses的对象，调用时需持有锁
+func (p *Pool) borrowShared() (interface{}, bool) {
+	max := p.maxUses()
+	if max <= 1 || p.closed {
+		return nil, false
+	}
+	for obj, c := range p.shared {
+		if !c.broken && c.refs < max {
+			c.refs++
+			if c.readOnly {
+				p.sharedRefs++
+			}
+			return obj, true
+		}
+	}
+	return nil, false
+}
+
+// share 开始共享一个新借出的对象，设置了ShareReadOnly时只共享只读的对象
+func (p *Pool) share(obj interface{}) {
+	p.mu.Lock()
+	readOnly := false
+	if isReadOnly := p.IsReadOnly; p.ShareReadOnly && isReadOnly != nil {
+		p.mu.Unlock()
+		if !isReadOnly(obj) {
+			return
+		}
+		readOnly = true
+		p.mu.Lock()
+	}
+	if p.shared == nil {
+		p.shared = make(map[interface{}]*sharedConn)
+	}
+	p.shared[obj] = &sharedConn{refs: 1, readOnly: readOnly}
+	p.mu.Unlock()
+}
+
+// returnShared 减少obj的借用者，还有其他借用者时inUse为true；
+// 最后一个借用者放回、且之前有借用者放回了错误时drop为true。调用时需持有锁
+func (p *Pool) returnShared(obj interface{}, broken bool) (inUse, drop bool) {
+	if len(p.shared) == 0 { // 避免对象不能作为map的key时panic
+		return false, false
+	}
+	c, ok := p.shared[obj]
+	if !ok {
+		return false, false
+	}
+	c.refs--
+	c.broken = c.broken || broken
+	if c.refs > 0 {
+		if c.readOnly {
+			p.sharedRefs--
+		}
+		return true, false
+	}
+	delete(p.shared, obj)
+	return false, c.broken
+}
@@ -0,0 +1,111 @@
+package pool
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPoolMaxConcurrentUses(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+	p.MaxConcurrentUses = 2
+
+	o1, _ := p.Get()
+	o2, _ := p.Get()
+	if o1 != o2 {
+		t.Errorf("o1=%v o2=%v", o1, o2)
+	}
+	d.check("shared", p, 1, 1)
+
+	p.Put(o1)
+	if n := p.IdleCount(); n != 0 {
+		t.Errorf("IdleCount()=%d, want 0", n)
+	}
+	if o, _ := p.Get(); o != o1 { // 还可以被再借出一次
+		t.Errorf("Get()=%v, want %v", o, o1)
+	}
+	p.Put(o1)
+	p.Put(o1)
+	if n := p.IdleCount(); n != 1 {
+		t.Errorf("IdleCount()=%d, want 1", n)
+	}
+
+	// 有借用者放回错误后不再借出，最后一个借用者放回时丢弃
+	o, _ := p.Get()
+	o3, _ := p.Get()
+	p.PutErr(o, errors.New("broken"))
+	o4, _ := p.Get()
+	if o4 == o {
+		t.Error("broken object borrowed again")
+	}
+	d.check("broken", p, 2, 2)
+	p.Put(o3)
+	d.check("drop broken", p, 2, 1)
+	p.PutErr(o4, errors.New("broken")) // 只有一个借用者，直接丢弃
+	d.check("drop o4", p, 2, 0)
+	p.Close()
+	d.check("after close", p, 2, 0)
+}
+
+func TestPoolMaxPipelineDepth(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+	p.MaxPipelineDepth = 3
+	p.IdleTimeout = time.Millisecond
+	defer p.Close()
+
+	o1, _ := p.Get()
+	time.Sleep(5 * time.Millisecond) // 正在使用的连接不受IdleTimeout影响
+	o2, _ := p.Get()
+	o3, _ := p.Get()
+	o4, _ := p.Get()
+	if o1 != o2 || o1 != o3 || o1 == o4 {
+		t.Errorf("o1=%v o2=%v o3=%v o4=%v", o1, o2, o3, o4)
+	}
+	d.check("pipelined", p, 2, 2)
+	for _, o := range []interface{}{o1, o2, o3, o4} {
+		p.Put(o)
+	}
+	if n := p.IdleCount(); n != 2 {
+		t.Errorf("IdleCount()=%d, want 2", n)
+	}
+}
+
+func TestPoolShareReadOnly(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+	p.ShareReadOnly = true
+	p.IsReadOnly = func(obj interface{}) bool { return obj.(*conn).id == 2 }
+	defer p.Close()
+
+	o1, _ := p.Get() // 不是只读的，不共享
+	o2, _ := p.Get()
+	for i := 0; i < 3; i++ {
+		if o, _ := p.Get(); o != o2 {
+			t.Fatalf("Get()=%v, want the shared %v", o, o2)
+		}
+	}
+	if o1 == o2 {
+		t.Error("non read-only object was shared")
+	}
+	if n := p.ActiveCount(); n != 5 { // 按借用计数
+		t.Errorf("ActiveCount()=%d, want 5", n)
+	}
+
+	p.Put(o1)
+	for i := 0; i < 3; i++ {
+		p.Put(o2)
+	}
+	if n := p.IdleCount(); n != 1 {
+		t.Errorf("IdleCount()=%d with a borrower left, want 1", n)
+	}
+	d.check("one borrower left", p, 2, 2)
+	p.Put(o2)
+	if n := p.IdleCount(); n != 2 {
+		t.Errorf("IdleCount()=%d, want 2", n)
+	}
+}
@@ -0,0 +1,38 @@
+package pool
+
+import "time"
+
+// Observer 用于观察pool中对象的创建、借出、放回和丢弃，所有方法都在不持有锁时同步调用
+type Observer interface {
+	OnGet(obj interface{}, reused bool, waitDuration time.Duration) // reused表示是否是空闲对象，waitDuration是等待可用对象的时间
+	OnPut(obj interface{}, dropped bool)                            // dropped表示对象是否被丢弃而不是放回空闲列表
+	OnCreate(obj interface{})
+	OnDestroy(obj interface{})
+}
+
+// RequestObserver 可以由Observer额外实现，通过GetWithRequestID借出的对象在OnGet和OnPut之后还会调用这些方法。
+// 开启TrackActive时Put()才能找到对象的请求ID
+type RequestObserver interface {
+	OnGetRequest(obj interface{}, reqID string)
+	OnPutRequest(obj interface{}, reqID string)
+}
+
+// observeRequest 在o实现了RequestObserver且reqID不为空时通知它，调用时不能持有锁
+func observeRequest(o Observer, obj interface{}, reqID string, put bool) {
+	ro, ok := o.(RequestObserver)
+	if !ok || reqID == "" {
+		return
+	}
+	if put {
+		ro.OnPutRequest(obj, reqID)
+	} else {
+		ro.OnGetRequest(obj, reqID)
+	}
+}
+
+// SetObserver 设置Observer并替换之前设置的，为nil时不再通知
+func (p *Pool) SetObserver(o Observer) {
+	p.mu.Lock()
+	p.observer = o
+	p.mu.Unlock()
+}
@@ -0,0 +1,141 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type recordObserver struct {
+	calls []string
+}
+
+func (o *recordObserver) OnGet(obj interface{}, reused bool, waitDuration time.Duration) {
+	o.calls = append(o.calls, fmt.Sprintf("get %d %t", obj.(*conn).id, reused))
+}
+
+func (o *recordObserver) OnPut(obj interface{}, dropped bool) {
+	o.calls = append(o.calls, fmt.Sprintf("put %d %t", obj.(*conn).id, dropped))
+}
+
+func (o *recordObserver) OnCreate(obj interface{}) {
+	o.calls = append(o.calls, fmt.Sprintf("create %d", obj.(*conn).id))
+}
+
+func (o *recordObserver) OnDestroy(obj interface{}) {
+	o.calls = append(o.calls, fmt.Sprintf("destroy %d", obj.(*conn).id))
+}
+
+func TestPoolObserver(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 1)
+	p.DropCallback = d.drop
+	o := &recordObserver{}
+	p.SetObserver(o)
+
+	c1, _ := p.Get()
+	c2, _ := p.Get()
+	p.Put(c1)
+	p.Put(c2) // 超出MaxIdle，丢弃最旧的c1
+	c2, _ = p.Get()
+	p.PutErr(c2, errors.New("broken"))
+	p.SetObserver(nil)
+	c3, _ := p.Get()
+	p.Put(c3)
+	p.Close()
+
+	want := []string{
+		"create 1", "get 1 false",
+		"create 2", "get 2 false",
+		"put 1 false",
+		"destroy 1", "put 2 false",
+		"get 2 true",
+		"destroy 2", "put 2 true",
+	}
+	if !reflect.DeepEqual(o.calls, want) {
+		t.Errorf("calls=%v, want %v", o.calls, want)
+	}
+	d.check("after close", p, 3, 0)
+}
+
+type requestObserver struct {
+	recordObserver
+}
+
+func (o *requestObserver) OnGetRequest(obj interface{}, reqID string) {
+	o.calls = append(o.calls, fmt.Sprintf("get %d %s", obj.(*conn).id, reqID))
+}
+
+func (o *requestObserver) OnPutRequest(obj interface{}, reqID string) {
+	o.calls = append(o.calls, fmt.Sprintf("put %d %s", obj.(*conn).id, reqID))
+}
+
+func TestPoolGetWithRequestID(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 1)
+	p.TrackActive = true
+	o := &requestObserver{}
+	p.SetObserver(o)
+	log := NewAuditLog(10)
+	p.SetAuditLog(log)
+	defer p.Close()
+
+	c, _ := p.GetWithRequestID(context.Background(), "req-1")
+	if r := p.LeakReport()[""]; len(r) != 1 || r[0].RequestID != "req-1" {
+		t.Errorf("LeakReport()=%v, want req-1", r)
+	}
+	p.Put(c)
+	c, _ = p.Get() // 放回后请求ID被清除
+	p.Put(c)
+
+	want := []string{"create 1", "get 1 false", "get 1 req-1", "put 1 false", "put 1 req-1", "get 1 true", "put 1 false"}
+	if !reflect.DeepEqual(o.calls, want) {
+		t.Errorf("calls=%v, want %v", o.calls, want)
+	}
+	var ids []string
+	for _, e := range log.Entries() {
+		ids = append(ids, e.EventType.String()+":"+e.RequestID)
+	}
+	if got := fmt.Sprint(ids); got != "[created:req-1 borrowed:req-1 returned:req-1 borrowed: returned:]" {
+		t.Errorf("audit=%s", got)
+	}
+}
+
+func TestLatencyObserver(t *testing.T) {
+	o := NewLatencyObserver(100)
+	if o.P50() != 0 {
+		t.Errorf("P50()=%v without samples, want 0", o.P50())
+	}
+	for i := 1; i <= 100; i++ {
+		o.OnGet(nil, true, time.Duration(i)*time.Millisecond)
+	}
+	if got := o.P50(); got != 50*time.Millisecond {
+		t.Errorf("P50()=%v, want 50ms", got)
+	}
+	if got := o.P99(); got != 99*time.Millisecond {
+		t.Errorf("P99()=%v, want 99ms", got)
+	}
+
+	for i := 0; i < 1000; i++ { // 样本数不超过sampleSize
+		o.OnGet(nil, true, time.Second)
+	}
+	if n := len(o.samples); n != 100 {
+		t.Errorf("samples=%d, want 100", n)
+	}
+	if got := o.P50(); got != time.Second { // 大部分样本被替换
+		t.Errorf("P50()=%v after 1000 more samples, want 1s", got)
+	}
+
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 1)
+	p.SetObserver(o)
+	c, _ := p.Get()
+	p.Put(c)
+	p.Close()
+	if o.seen != 1101 {
+		t.Errorf("seen=%d, want 1101", o.seen)
+	}
+}
@@ -0,0 +1,66 @@
+package pool
+
+import (
+	"reflect"
+	"time"
+)
+
+// Option 用于设置Pool的参数
+type Option func(*Pool)
+
+func WithMaxIdle(n int) Option {
+	return func(p *Pool) { p.MaxIdle = n }
+}
+
+func WithMaxActive(n int) Option {
+	return func(p *Pool) { p.MaxActive = n }
+}
+
+func WithIdleTimeout(d time.Duration) Option {
+	return func(p *Pool) { p.IdleTimeout = d }
+}
+
+func WithWait(wait bool) Option {
+	return func(p *Pool) { p.Wait = wait }
+}
+
+func WithTestOnBorrow(fn func(interface{}) error) Option {
+	return func(p *Pool) { p.TestOnBorrow = fn }
+}
+
+func WithDropCallback(fn func(interface{})) Option {
+	return func(p *Pool) { p.DropCallback = fn }
+}
+
+// Clone 创建一个新的Pool，复制p所有导出的字段后再应用opts。
+// 新的Pool没有任何空闲对象，和p互不影响；Endpoints等slice会被复制，修改其中一个不影响另一个。
+// GetRateLimiter、ShrinkPolicy、Logger等接口字段和回调函数与p共享同一个实例，
+// 如需要独立的令牌桶，用opts设置新的GetRateLimiter
+func (p *Pool) Clone(opts ...Option) *Pool {
+	c := &Pool{}
+	p.mu.Lock()
+	src, dst := reflect.ValueOf(p).Elem(), reflect.ValueOf(c).Elem()
+	for i := 0; i < src.NumField(); i++ {
+		if !src.Type().Field(i).IsExported() {
+			continue
+		}
+		f := src.Field(i)
+		switch {
+		case f.Kind() == reflect.Slice && !f.IsNil():
+			f = reflect.AppendSlice(reflect.MakeSlice(f.Type(), 0, f.Len()), f)
+		case f.Kind() == reflect.Map && !f.IsNil():
+			m := reflect.MakeMapWithSize(f.Type(), f.Len())
+			for it := f.MapRange(); it.Next(); {
+				m.SetMapIndex(it.Key(), it.Value())
+			}
+			f = m
+		}
+		dst.Field(i).Set(f)
+	}
+	p.mu.Unlock()
+
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
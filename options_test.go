@@ -0,0 +1,52 @@
+package pool
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPoolClone(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+
+	o, _ := p.Get()
+	p.Put(o)
+
+	c := p.Clone(WithMaxActive(1))
+	if c.MaxIdle != 2 || c.MaxActive != 1 {
+		t.Errorf("MaxIdle=%d MaxActive=%d, want 2 1", c.MaxIdle, c.MaxActive)
+	}
+	if p.MaxActive != 0 {
+		t.Errorf("source MaxActive=%d, want 0", p.MaxActive)
+	}
+
+	o, err := c.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get(); err != ErrPoolExhausted {
+		t.Errorf("err=%v, want %v", err, ErrPoolExhausted)
+	}
+	if p.ActiveCount() != 1 || c.ActiveCount() != 1 {
+		t.Errorf("active=%d clone active=%d, want 1 1", p.ActiveCount(), c.ActiveCount())
+	}
+	c.Put(o)
+	c.Close()
+	d.check("clone closed", p, 2, 1)
+	p.Close()
+	d.check("closed", p, 2, 0)
+}
+
+func TestPoolCloneEndpoints(t *testing.T) {
+	p := &Pool{
+		NewWithEndpoint: func(endpoint string) (interface{}, error) { return endpoint, nil },
+		Endpoints:       []string{"a", "b"},
+	}
+	c := p.Clone()
+	c.Endpoints[0] = "c"
+	c.Endpoints = append(c.Endpoints, "d")
+	if !reflect.DeepEqual(p.Endpoints, []string{"a", "b"}) {
+		t.Errorf("source Endpoints=%v after modifying the clone, want [a b]", p.Endpoints)
+	}
+}
@@ -0,0 +1,74 @@
+// Package otel 为pool.Pool提供OpenTelemetry链路追踪，tracer由调用者传入，不使用全局的TracerProvider
+package otel
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/chen-zyc/pool"
+)
+
+var _ pool.PoolIface = (*TracedPool)(nil)
+
+// TracedPool 包装了pool.Pool，Get()和Put()时会创建span
+type TracedPool struct {
+	*pool.Pool
+
+	tracer trace.Tracer
+	name   string
+}
+
+func NewTracedPool(p *pool.Pool, tracer trace.Tracer, poolName string) *TracedPool {
+	return &TracedPool{Pool: p, tracer: tracer, name: poolName}
+}
+
+func (p *TracedPool) Get() (interface{}, error) {
+	return p.GetContext(context.Background())
+}
+
+// GetContext 创建名为pool.get的span，包括pool的名字以及借出前的活跃和空闲对象数量，
+// 需要等待可用对象时会添加pool.wait事件
+func (p *TracedPool) GetContext(ctx context.Context) (interface{}, error) {
+	ctx, span := p.tracer.Start(ctx, "pool.get", trace.WithAttributes(
+		attribute.String("pool.name", p.name),
+		attribute.Int("pool.active", p.Pool.ActiveCount()),
+		attribute.Int("pool.idle", p.Pool.IdleCount()),
+	))
+	defer span.End()
+
+	ctx = pool.WithGetTrace(ctx, &pool.GetTrace{
+		WaitStart: func() { span.AddEvent("pool.wait") },
+	})
+	obj, err := p.Pool.GetContext(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return obj, err
+}
+
+// Put 创建名为pool.put的span并放回obj
+func (p *TracedPool) Put(obj interface{}) {
+	_, span := p.tracer.Start(context.Background(), "pool.put", trace.WithAttributes(
+		attribute.String("pool.name", p.name),
+	))
+	p.Pool.Put(obj)
+	span.End()
+}
+
+// PutErr 创建名为pool.put的span，err不为nil时对象被丢弃，span记录err并设置为错误状态
+func (p *TracedPool) PutErr(obj interface{}, err error) {
+	_, span := p.tracer.Start(context.Background(), "pool.put", trace.WithAttributes(
+		attribute.String("pool.name", p.name),
+		attribute.Bool("pool.discarded", err != nil),
+	))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	p.Pool.PutErr(obj, err)
+	span.End()
+}
@@ -0,0 +1,87 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/chen-zyc/pool"
+)
+
+func TestTracedPool(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	p := pool.NewPool(func() (interface{}, error) { return new(int), nil }, 1)
+	p.MaxActive = 1
+	p.Wait = true
+	traced := NewTracedPool(p, tp.Tracer("test"), "db")
+	defer traced.Close()
+
+	o, err := traced.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		o, _ := traced.GetContext(context.Background()) // 需要等待
+		traced.Put(o)
+		close(done)
+	}()
+	for p.WaiterCount() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	traced.Put(o)
+	<-done
+
+	var names []string
+	waits := 0
+	for _, s := range sr.Ended() {
+		names = append(names, s.Name())
+		for _, e := range s.Events() {
+			if e.Name == "pool.wait" {
+				waits++
+			}
+		}
+		if s.Name() == "pool.get" {
+			found := false
+			for _, kv := range s.Attributes() {
+				found = found || (kv.Key == "pool.name" && kv.Value.AsString() == "db")
+			}
+			if !found {
+				t.Errorf("span %s has no pool.name attribute", s.Name())
+			}
+		}
+	}
+	if len(names) != 4 {
+		t.Errorf("ended spans %v, want 2 gets and 2 puts", names)
+	}
+	if waits != 1 {
+		t.Errorf("pool.wait events=%d, want 1", waits)
+	}
+}
+
+func TestTracedPoolPutErr(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	p := pool.NewPool(func() (interface{}, error) { return new(int), nil }, 1)
+	traced := NewTracedPool(p, tp.Tracer("test"), "db")
+	defer traced.Close()
+
+	o, _ := traced.Get()
+	traced.PutErr(o, errors.New("broken"))
+	spans := sr.Ended()
+	if len(spans) != 2 || spans[1].Name() != "pool.put" {
+		t.Fatalf("ended spans %v, want get and put", spans)
+	}
+	if s := spans[1].Status(); s.Code != codes.Error || s.Description != "broken" {
+		t.Errorf("put span status=%v, want error", s)
+	}
+	if n := p.IdleCount(); n != 0 {
+		t.Errorf("IdleCount()=%d, want 0", n)
+	}
+}
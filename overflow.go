@@ -0,0 +1,76 @@
+package pool
+
+import (
+	"context"
+	"errors"
+)
+
+// GracefulGet 依次尝试借出空闲对象、创建新对象，达到MaxActive时即使Wait为false也会等待最多GracefulWaitTime，
+// 仍然没有可用的对象时创建一个临时对象（最多MaxOverflow个），临时对象放回时会被丢弃；都不行时返回ErrPoolExhausted。
+// 临时对象记录在以对象为key的map中，对象需要能作为map的key
+func (p *Pool) GracefulGet(ctx context.Context) (interface{}, error) {
+	p.mu.Lock()
+	waitTime := p.GracefulWaitTime
+	p.mu.Unlock()
+	if waitTime <= 0 {
+		waitTime = -1 // 不等待
+	}
+	obj, err := p.get(ctx, getOptions{waitTimeout: waitTime})
+	if err == nil || ctx.Err() != nil || !errors.Is(err, ErrPoolExhausted) {
+		return obj, err
+	}
+	return p.getOverflow(ctx)
+}
+
+// getOverflow 创建一个不计入active的临时对象，超出MaxOverflow时返回ErrPoolExhausted
+func (p *Pool) getOverflow(ctx context.Context) (interface{}, error) {
+	p.mu.Lock()
+	if p.closed {
+		err := p.err(ErrPoolClosed)
+		p.mu.Unlock()
+		return nil, err
+	}
+	if p.overflowing >= p.MaxOverflow {
+		err := p.err(ErrPoolExhausted)
+		p.mu.Unlock()
+		return nil, err
+	}
+	dial, _, err := p.dialer()
+	if err != nil {
+		p.mu.Unlock()
+		return nil, err
+	}
+	p.overflowing++
+	events := p.sink()
+	p.mu.Unlock()
+
+	obj, err := dial(ctx)
+	publish(events, EventCreated, obj, err)
+	p.mu.Lock()
+	if err != nil {
+		p.overflowing--
+		p.dialFailed(err)
+		p.mu.Unlock()
+		return nil, err
+	}
+	if p.overflow == nil {
+		p.overflow = make(map[interface{}]struct{})
+	}
+	p.overflow[obj] = struct{}{}
+	p.mu.Unlock()
+	p.stats.misses.Add(1)
+	return obj, nil
+}
+
+// fromOverflow 返回obj是否是GracefulGet创建的临时对象，并删除记录，调用时需持有锁
+func (p *Pool) fromOverflow(obj interface{}) bool {
+	if len(p.overflow) == 0 { // 避免对象不能作为map的key时panic
+		return false
+	}
+	if _, ok := p.overflow[obj]; !ok {
+		return false
+	}
+	delete(p.overflow, obj)
+	p.overflowing--
+	return true
+}
@@ -0,0 +1,56 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPoolGracefulGet(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 1)
+	p.DropCallback = d.drop
+	p.MaxActive = 1
+	p.GracefulWaitTime = 10 * time.Millisecond
+	p.MaxOverflow = 1
+	defer p.Close()
+
+	o1, err := p.GracefulGet(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	o2, err := p.GracefulGet(context.Background()) // 等待之后创建临时对象
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+		t.Errorf("overflow after %v, want >= 10ms", elapsed)
+	}
+	if _, err := p.GracefulGet(context.Background()); !errors.Is(err, ErrPoolExhausted) {
+		t.Errorf("GracefulGet()=%v, want ErrPoolExhausted", err)
+	}
+	if n := p.ActiveCount(); n != 1 {
+		t.Errorf("ActiveCount()=%d, want 1", n)
+	}
+
+	p.Put(o2) // 临时对象放回时被丢弃
+	if d.open != 1 || p.IdleCount() != 0 {
+		t.Errorf("after putting overflow: open=%d, idle=%d, want 1 and 0", d.open, p.IdleCount())
+	}
+
+	// 等待期间放回的对象会被借出
+	go func() {
+		time.Sleep(time.Millisecond)
+		p.Put(o1)
+	}()
+	p.mu.Lock()
+	p.GracefulWaitTime = time.Second
+	p.mu.Unlock()
+	if o, err := p.GracefulGet(context.Background()); o != o1 || err != nil {
+		t.Errorf("GracefulGet()=%v, %v, want %v", o, err, o1)
+	}
+	p.Put(o1)
+	d.check("graceful", p, 2, 1)
+}
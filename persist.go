@@ -0,0 +1,107 @@
+package pool
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Serializable 是可以保存到磁盘并在之后恢复的对象，见SaveState和LoadState。
+// UnmarshalConn会在一个零值（指针类型时为指向零值的指针）上调用，返回恢复的对象
+type Serializable interface {
+	MarshalConn() ([]byte, error)
+	UnmarshalConn([]byte) (interface{}, error)
+}
+
+var serializables sync.Map // 类型名 -> reflect.Type
+
+// RegisterSerializable 注册v的类型，LoadState只能恢复注册过的类型，与gob.Register类似。
+// SaveState会自动注册它保存的对象的类型
+func RegisterSerializable(v Serializable) {
+	t := reflect.TypeOf(v)
+	serializables.Store(serialName(t), t)
+}
+
+// serialName 返回t包含完整包路径的名字
+func serialName(t reflect.Type) string {
+	if t.Kind() == reflect.Ptr {
+		return "*" + serialName(t.Elem())
+	}
+	if t.Name() != "" && t.PkgPath() != "" {
+		return t.PkgPath() + "." + t.Name()
+	}
+	return t.String()
+}
+
+type savedConn struct {
+	Type      string    `json:"type"`
+	Data      []byte    `json:"data"`
+	CreatedAt time.Time `json:"created_at"`
+	Uses      int       `json:"uses"`
+}
+
+// SaveState 把实现了Serializable的空闲对象及其创建时间、使用次数写入w，其他的对象会被跳过。
+// 对象仍然留在pool中，一般在关闭之前调用
+func (p *Pool) SaveState(w io.Writer) error {
+	infos := p.IdleConnections()
+	saved := make([]savedConn, 0, len(infos))
+	for _, info := range infos {
+		s, ok := info.Obj.(Serializable)
+		if !ok {
+			continue
+		}
+		data, err := s.MarshalConn()
+		if err != nil {
+			return p.serialErr(fmt.Sprintf("pool: marshal %T", info.Obj), err)
+		}
+		RegisterSerializable(s)
+		saved = append(saved, savedConn{Type: serialName(reflect.TypeOf(s)), Data: data, CreatedAt: info.CreatedAt, Uses: info.Uses})
+	}
+	return json.NewEncoder(w).Encode(saved)
+}
+
+// LoadState 从r中恢复SaveState保存的对象并作为空闲对象放入pool，保留原来的创建时间和使用次数。
+// 没有注册的类型会被跳过，超出MaxIdle或MaxActive的对象会被丢弃；
+// UnmarshalConn失败的对象也会被跳过，其他对象恢复之后返回第一个错误
+func (p *Pool) LoadState(r io.Reader) error {
+	var saved []savedConn
+	if err := json.NewDecoder(r).Decode(&saved); err != nil {
+		return p.serialErr("pool: decode state", err)
+	}
+	infos := make([]ConnectionInfo, 0, len(saved))
+	var firstErr error
+	for i := len(saved) - 1; i >= 0; i-- { // importIdle依次放到最前面，倒序放入以保持原来的顺序
+		sc := saved[i]
+		v, ok := serializables.Load(sc.Type)
+		if !ok {
+			continue
+		}
+		t := v.(reflect.Type)
+		var zero interface{}
+		if t.Kind() == reflect.Ptr {
+			zero = reflect.New(t.Elem()).Interface()
+		} else {
+			zero = reflect.Zero(t).Interface()
+		}
+		obj, err := zero.(Serializable).UnmarshalConn(sc.Data)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = p.serialErr("pool: unmarshal "+sc.Type, err)
+			}
+			continue
+		}
+		infos = append(infos, ConnectionInfo{Obj: obj, CreatedAt: sc.CreatedAt, Uses: sc.Uses})
+	}
+	p.importIdle(infos, false)
+	return firstErr
+}
+
+// serialErr 返回Code为ErrCodeSerialization的错误，err为失败的原因
+func (p *Pool) serialErr(msg string, err error) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err(&PoolError{Code: ErrCodeSerialization, Msg: msg, Err: err})
+}
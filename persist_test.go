@@ -0,0 +1,76 @@
+package pool
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+type savedTestConn struct {
+	addr string
+}
+
+func (c *savedTestConn) MarshalConn() ([]byte, error) {
+	if c.addr == "" {
+		return nil, errors.New("no addr")
+	}
+	return []byte(c.addr), nil
+}
+
+func (*savedTestConn) UnmarshalConn(b []byte) (interface{}, error) {
+	if string(b) == "bad" {
+		return nil, errors.New("bad addr")
+	}
+	return &savedTestConn{addr: string(b)}, nil
+}
+
+func TestPoolSaveLoadState(t *testing.T) {
+	n := 0
+	p := NewPool(func() (interface{}, error) {
+		n++
+		return &savedTestConn{addr: "host:" + strconv.Itoa(n)}, nil
+	}, 3)
+	p.TrackActive = true // 记录使用次数
+	o1, _ := p.Get()
+	o2, _ := p.Get()
+	p.Put(o1)
+	p.Put(o2)
+
+	var buf bytes.Buffer
+	if err := p.SaveState(&buf); err != nil {
+		t.Fatal(err)
+	}
+	p.Close()
+
+	p2 := NewPool(nil, 4)
+	defer p2.Close()
+	if err := p2.LoadState(&buf); err != nil {
+		t.Fatal(err)
+	}
+	infos := p2.IdleConnections()
+	if len(infos) != 2 {
+		t.Fatalf("IdleConnections()=%v, want 2", infos)
+	}
+	if addr := infos[0].Obj.(*savedTestConn).addr; addr != "host:2" { // 保持原来的顺序
+		t.Errorf("first idle addr=%q, want host:2", addr)
+	}
+	if infos[0].Uses != 1 {
+		t.Errorf("Uses=%d, want 1", infos[0].Uses)
+	}
+
+	p2.Put(&savedTestConn{addr: "bad"})
+	p2.Put(&conn{}) // 不能序列化的对象会被跳过
+	buf.Reset()
+	if err := p2.SaveState(&buf); err != nil {
+		t.Fatal(err)
+	}
+	p3 := NewPool(nil, 3)
+	defer p3.Close()
+	if err := p3.LoadState(&buf); !errors.Is(err, &PoolError{Code: ErrCodeSerialization}) {
+		t.Errorf("LoadState() with a bad object: err=%v, want ErrCodeSerialization", err)
+	}
+	if n := p3.IdleCount(); n != 2 {
+		t.Errorf("IdleCount()=%d, want 2", n)
+	}
+}
@@ -1,37 +1,344 @@
 package pool
 
 import (
-	"container/list"
+	"context"
 	"errors"
+	"io"
+	"log/slog"
+	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-var nowFunc = time.Now // for test
-
 var (
-	ErrPoolClosed    = errors.New("pool closed")
-	ErrPoolExhausted = errors.New("pool exhausted")
+	nowFunc    = time.Now    // for test
+	randInt63n = rand.Int63n // for test
 )
 
 type Pool struct {
+	Name string // pool的名字，会出现在错误信息中
+
+	// New、TestOnBorrow、DropCallback、MaxIdle、MaxActive和IdleTimeout在pool使用之后不能直接修改，需要使用对应的Set方法
 	New          func() (interface{}, error)
 	TestOnBorrow func(interface{}) error
 	DropCallback func(interface{}) // 丢弃对象的回调
-	MaxIdle      int
+	MaxIdle      int               // 空闲对象保存在idleList中，不区分MaxIdle的大小，见idleList
 	MaxActive    int
 	IdleTimeout  time.Duration
 	Wait         bool // 如果为true，当pool达到MaxActive后，会等待一个对象返回到pool中
+	MaxWaiters   int  // Wait为true时最多允许多少个goroutine等待，超出时返回ErrTooManyWaiters，为0时不限制
+	SelectLRU    bool // 为true时优先借出空闲最久的对象，否则优先借出最近放回的对象
+
+	// 为true时放回的对象按等待的先后（优先级相同时先来先得）分配给等待者，被分配的对象在等待者取走之前不会被其他goroutine借出，
+	// 即使它们是GetTagged等有偏好的调用，用于避免后来的调用者抢走对象使等待者饿死
+	FairGet bool
+
+	// Wait为true时每次Get()最多等待多久，超时返回Code为ErrCodeWaitTimeout、包装了context.DeadlineExceeded的错误，为0时不限制
+	WaitTimeout time.Duration
+
+	// GracefulGet在达到MaxActive时最多等待GracefulWaitTime，之后创建最多MaxOverflow个临时对象，
+	// 临时对象不计入active，放回时会被丢弃。对象需要能作为map的key
+	GracefulWaitTime time.Duration
+	MaxOverflow      int
+
+	// BatchDo最多同时运行多少个函数，为0时不限制
+	BatchConcurrency int
+
+	// 大于0时空闲对象的上限为int(MaxIdlePercent * MaxActive)，代替MaxIdle，MaxActive为0时仍然使用MaxIdle
+	MaxIdlePercent float64
+
+	// Get()返回ErrPoolExhausted时调用，两次调用至少间隔OnExhaustedThrottle
+	OnExhausted         func()
+	OnExhaustedThrottle time.Duration
+
+	// 设置后每次Get()在加锁之前先调用GetRateLimiter.Wait(ctx)限制获取对象的速率，可以直接使用*rate.Limiter
+	// （golang.org/x/time/rate）。为nil时不限制，需要在使用pool前设置
+	GetRateLimiter RateLimiter
+
+	// IsHealthy()在最近一次创建对象失败后的ErrorRecencyWindow内返回false，为0时不考虑创建失败
+	ErrorRecencyWindow time.Duration
+
+	// AllErrors()最多保存的错误数，为0时为32
+	ErrorBufferSize int
+
+	// LifetimePercentile()和AverageLifetime()最多使用最近多少个对象的存活时间，为0时为1024
+	LifetimeSampleSize int
+
+	// 大于0时每次创建对象最多等待DialTimeout，超时返回ErrDialTimeout。设置了NewContext时通过ctx的deadline传入，
+	// 超时后不再等待New或NewContext返回，之后创建的对象会被丢弃（调用DropCallback）
+	DialTimeout time.Duration
+
+	// 为true时同一时刻只有一个goroutine在创建对象，其他的排队等待，用于避免同时大量创建对象压垮下游。
+	// MaxConcurrentDials大于0时最多允许MaxConcurrentDials个同时创建（不需要再设置SerializeDial），为0时不限制。
+	// 排队超过WaitTimeout时返回ErrWaitTimeout。因DialTimeout或ctx放弃的创建在New返回之前仍然占用名额，排队的时间也计入DialTimeout。
+	// 修改上限之后的创建使用新的上限，已经在创建或排队的仍然按旧的上限计算
+	SerializeDial      bool
+	MaxConcurrentDials int
+	MaxDialConcurrency int // 与MaxConcurrentDials相同，两个都设置时使用较大的
+
+	// 大于0时限制每秒最多创建MaxDialRate个对象，最多可以连续创建MaxDialBurst个（至少为1）。
+	// 超出时与达到MaxActive相同：Wait为true则等待，否则返回ErrPoolExhausted
+	MaxDialRate  float64
+	MaxDialBurst int
+
+	// 最多允许多少个goroutine同时在Get()中，避免pool为空时大量goroutine同时创建对象。
+	// 超出时Wait为true则等待，否则返回ErrPoolExhausted，为0时不限制。需要在使用pool前设置
+	MaxConcurrentGet int
+
+	// 每个goroutine最多同时借出多少个对象，超出时Get()返回ErrBorrowLimitExceeded，为0时不限制。对象需要能作为map的key
+	MaxBorrowsPerGoroutine int
+
+	// 代替New创建对象，GetContext会把ctx传给它，New、NewContext和NewWithEndpoint只能设置一个
+	NewContext func(context.Context) (interface{}, error)
+
+	// 代替New创建对象，每次按轮询的顺序使用Endpoints中的一个，对象的endpoint会被记录下来，
+	// GetFromEndpoint()可以优先借出指定endpoint的对象。对象需要能作为map的key
+	Endpoints       []string
+	NewWithEndpoint func(endpoint string) (interface{}, error)
+
+	// 为true时记录所有借出的对象，对象需要能作为map的key，需要在使用pool前设置
+	TrackActive bool
+	TrackState  bool // 为true时记录每个对象的状态，可以通过Connections()查看，对象需要能作为map的key
+
+	// 对象借出超过BorrowDeadline后，janitor会调用OnBorrowDeadlineExceeded并不再把它计入active，
+	// 需要开启TrackActive并设置JanitorInterval。对象本身不会被关闭，之后放回时会被直接丢弃
+	BorrowDeadline           time.Duration
+	OnBorrowDeadlineExceeded func(obj interface{})
+
+	// 有goroutine在等待时，每隔PreemptTimeout对借出超过PreemptTimeout的对象调用PreemptCallback，
+	// 返回true表示借用者已经放弃了这个对象，它不再计入active，之后放回时会被直接丢弃；
+	// 返回false时再过PreemptTimeout才会再次询问。需要开启TrackActive
+	PreemptTimeout  time.Duration
+	PreemptCallback func(obj interface{}) bool
+
+	// 设置后代替TestOnBorrow，uses为对象之前被借出的次数。对象需要能作为map的key
+	TestOnBorrowWithCount func(obj interface{}, uses int) error
+
+	// TestOnBorrow（或TestOnBorrowWithCount）失败时最多重试TestOnBorrowRetries次，每次间隔TestOnBorrowRetryDelay，
+	// 都失败时才丢弃对象
+	TestOnBorrowRetries    int
+	TestOnBorrowRetryDelay time.Duration
+
+	// 大于1时Get()会同时对最多PrevalidationConcurrency个空闲对象（与只检查一个时的选择规则相同）调用TestOnBorrow，借出最先通过检查的，
+	// 其他通过检查的对象放回空闲列表，适用于不可用的对象较多的场景。不会重试，设置了TestOnBorrowWithCount时不生效
+	PrevalidationConcurrency int
+
+	// Warmup创建对象后调用，如进行认证或设置会话变量，返回错误时对象会被丢弃。Get()创建的对象不会调用
+	OnWarmup func(interface{}) error
+
+	// 对象放回空闲列表前调用，用于重置对象的状态，返回错误时对象会被丢弃
+	ResetCallback func(interface{}) error
+
+	// 每个对象的超时时间会额外加上创建时抽取的[0, IdleTimeoutJitter)的随机值，避免同时创建的对象同时过期。
+	// 需要记录借出的对象，对象需要能作为map的key
+	IdleTimeoutJitter time.Duration
+	JanitorInterval   time.Duration // 每隔多久在后台清除一次过期的空闲对象，为0时只在Get()时清除
+
+	// janitor每次运行时调用ShrinkPolicy.ShouldShrink，从最旧的开始丢弃返回数量的空闲对象，
+	// 为nil时不丢弃（与NoShrink相同）。需要设置JanitorInterval
+	ShrinkPolicy ShrinkPolicy
+
+	// janitor每次运行时对空闲对象调用HealthScore打分（越大越好），Get()优先借出分数最高的空闲对象（按分数保存在堆中，不需要遍历）。
+	// EvictScoreThreshold大于0时分数低于它的空闲对象会被丢弃。需要设置JanitorInterval，对象需要能作为map的key
+	HealthScore         func(obj interface{}) float64
+	EvictScoreThreshold float64
+
+	// 通过SetWarmingStrategy设置的预热策略在后台补充空闲对象的目标数量
+	MinIdle int
+
+	// 空闲对象因为过期或超出MaxIdle被淘汰时调用Recycle代替DropCallback，借出后丢弃的对象不受影响，
+	// Drain、Compact、FlushAndReload以及版本过时等主动丢弃的对象也直接调用DropCallback。
+	// Recycle可以重置对象后返回它，空闲对象没有超出MaxIdle时会放回空闲列表（保留原来的创建时间和版本），否则调用DropCallback；
+	// 返回nil时pool不再处理这个对象，如Recycle已经把它放到了sync.Pool中。被Recycle处理的对象不会产生EventDestroyed和OnDestroy，对象需要能作为map的key
+	Recycle func(obj interface{}) interface{}
+
+	// 为true时Put()会对io.ReadWriteCloser写入0个字节，返回错误（如已经被关闭）的对象与PutErr()一样被丢弃；
+	// Put()或PutErr()丢弃对象后，如果空闲对象少于MinIdle，会立即在后台创建一个对象放入空闲列表，不阻塞调用者，pool关闭时取消
+	RefillOnDrop bool
+
+	// Wait为false且达到MaxActive、没有空闲对象时，每隔ExhaustionProbeInterval在后台尝试创建一个对象
+	// 并放入空闲列表（超时时间也是ExhaustionProbeInterval），使之后的Get()不再返回ErrPoolExhausted。
+	// 探测创建的对象计入active，所以active最多比MaxActive多一个；连续失败时探测间隔会逐渐变长
+	ExhaustionProbeInterval time.Duration
+
+	// 空闲超过MaxIdleTime的对象会被移出空闲列表但不会被丢弃，没有其他空闲对象时仍然可以被借出，
+	// 但放回时会被丢弃。和IdleTimeout的区别是IdleTimeout会直接丢弃对象
+	MaxIdleTime time.Duration
+
+	// 大于1时一个对象可以同时被借出MaxConcurrentUses次（如HTTP/2连接），对象需要能作为map的key。
+	// 所有借用者都放回后对象才会回到空闲列表，ActiveCount()仍然按对象计数
+	MaxConcurrentUses int
+
+	// 与MaxConcurrentUses相同，用于Redis等支持pipeline的连接：还有请求在进行的连接可以继续被借出，
+	// 不在空闲列表中，也不受IdleTimeout影响。两个都设置时使用较大的
+	MaxPipelineDepth int
+
+	// ShareReadOnly为true时IsReadOnly返回true的对象（如只读的数据库连接）可以同时被任意多个借用者使用，
+	// 设置了MaxConcurrentUses时不超过MaxConcurrentUses；其他对象不共享。ActiveCount()按借用计数，共享的只读对象
+	// 有几个借用者就算几个，MaxActive等限制和其他统计仍然按对象计算
+	ShareReadOnly bool
+	IsReadOnly    func(obj interface{}) bool
+
+	// 慢启动：刚开始最多只能有SlowStartInitial个对象，每隔SlowStartInterval增加SlowStartStep个，直到MaxActive。
+	// 需要设置MaxActive
+	SlowStartInitial  int
+	SlowStartStep     int
+	SlowStartInterval time.Duration
+
+	// 根据等待者数量自动调整MaxActive，每隔AutoScaleInterval检查一次
+	AutoScale         bool
+	AutoScaleMin      int
+	AutoScaleMax      int
+	AutoScaleStep     int
+	AutoScaleInterval time.Duration
+	Logger            io.Writer // 没有调用SetLogger时以文本格式输出Info及以上级别的日志，如自动调整MaxActive，为nil时不输出
+
+	// DegradationThreshold大于0且设置了MaxActive时，janitor统计创建对象和TestOnBorrow的错误率（指数加权移动平均），
+	// 超过DegradationThreshold时把有效的MaxActive乘以DegradationFactor（为0时为0.5）来减轻下游的压力，
+	// 低于RecoveryThreshold（为0时为DegradationThreshold的一半）后逐渐恢复。需要设置JanitorInterval
+	DegradationThreshold float64
+	DegradationFactor    float64
+	RecoveryThreshold    float64
+
+	KeepaliveInterval time.Duration           // 每隔多久对空闲对象调用一次Ping
+	Ping              func(interface{}) error // 返回错误时对象会被丢弃
+
+	HealthCheckInterval time.Duration // 每隔多久在后台对空闲对象调用一次TestOnBorrow
+
+	// 创建对象时计算对象的标签，GetTagged()优先返回标签相同的空闲对象。对象需要能作为map的key
+	Tag func(interface{}) interface{}
+
+	// 大于0时新创建的对象记录当前的ConnectionVersion，Get()时会丢弃版本更小的空闲对象，
+	// 用于在迁移等改变了连接状态的操作之后逐渐替换旧的连接，见SetVersion。对象需要能作为map的key
+	ConnectionVersion uint64
+
+	// 放回对象时计算对象的等级（0到3，越小越好，超出范围的按最近的算），Get()优先借出等级最小的空闲对象，
+	// 如网络路径更近的连接；超出MaxIdle时先淘汰等级最大的。每个等级的空闲对象保存在单独的列表中，选择时不需要遍历。
+	// 等级只在Put()时计算，预热等没有经过Put()的空闲对象为0
+	Tier func(obj interface{}) int
+
 	mu           sync.Mutex
-	cond         *sync.Cond
 	closed       bool
+	closeOnce    sync.Once // OnceClose使用
+	paused       bool
 	active       int
-	idle         list.List
+	waiters      waitHeap // 阻塞在Get()中的goroutine
+	waiterID     uint64
+	idle         idleList
+	nextEndpoint atomic.Int64  // 下一个对象使用的endpoint
+	getSem       chan struct{} // 限制同时在Get()中的goroutine，设置了MaxConcurrentGet时才会创建
+
+	lastErr   error // 最近一次创建对象失败的错误
+	lastErrAt time.Time
+
+	errBuf   []RecordedError // AllErrors()使用的环形缓冲区
+	errNext  int             // 下一个错误保存的位置
+	errCount int
+
+	lifetimes []time.Duration // 被丢弃的对象从创建到丢弃的时间，环形缓冲区
+	lifeNext  int
+	lifeCount int
+
+	warming    WarmingStrategy // 通过SetWarmingStrategy设置
+	warmStop   chan struct{}   // 关闭时当前的预热goroutine退出
+	dialBucket dialBucket      // 设置了MaxDialRate时限制创建对象的速率
+	dialSem    chan struct{}   // 设置了SerializeDial或MaxConcurrentDials时限制同时创建对象的数量，容量是maxDials()
+	dialing    atomic.Int64    // 正在调用New的数量
+
+	leases map[interface{}]*LeasedConn // Lease借出的对象
+
+	tokens    map[uint64]interface{} // Borrow借出的对象
+	lastToken uint64
+
+	limbo idleList // 空闲超过MaxIdleTime的对象
+
+	shared     map[interface{}]*sharedConn // 设置了MaxConcurrentUses时正在被使用的对象
+	sharedRefs int                         // 共享的只读对象除第一个之外的借用者数量，ActiveCount()使用
+
+	borrowed map[interface{}]ConnectionInfo // 借出对象的记录，只在tracking()为true时维护
+	closing  map[interface{}]int            // 正在调用DropCallback的对象，只在TrackState为true时维护
+	borrows  map[int64]int                  // 每个goroutine借出的对象数，只在设置了MaxBorrowsPerGoroutine时维护
+
+	events          chan PoolEvent // 调用Events()之后才会创建
+	eventBufferSize int
+
+	stats poolStats
+
+	group *PoolGroup // 通过PoolGroup.Add()设置
+
+	observer Observer  // 通过SetObserver()设置
+	audit    *AuditLog // 通过SetAuditLog()设置
+
+	generation uint64 // 每次Reset时加1
+
+	logger *slog.Logger // SetLogger设置的结构化日志
+	logs   []logRecord  // 持有锁时记录的日志，释放锁之后输出
+
+	meta map[interface{}]map[string]interface{} // SetMeta附加到对象上的元数据，第一次使用时创建
+
+	recycling map[interface{}]ConnectionInfo // 设置了Recycle时，超出MaxIdle或过期、等待dropObjs调用Recycle的空闲对象
+
+	overflow    map[interface{}]struct{} // GracefulGet创建的借出中的临时对象
+	overflowing int                      // 临时对象的数量，包括正在创建的
+
+	testHook *TestHook
+	dials    int // 设置testHook之后创建对象的次数
+
+	lastExhausted time.Time // 上次调用OnExhausted的时间
+
+	slowStarting    bool // 是否处于慢启动阶段
+	slowStartActive int  // 慢启动阶段的MaxActive
+
+	idleCh   chan struct{} // WaitForIdle和Shutdown等待的channel，空闲对象或active可能变化时关闭
+	assigned int           // 设置了FairGet时，已经分配给被唤醒的等待者但还没有被取走的空闲对象数
+
+	activeCaps map[uint64]int // ReduceMaxActive设置的临时上限
+	capSeq     uint64
+	throttle   int // Throttle减少的百分比，为0时不限制，为100时暂停借出
+
+	degradeMax     int     // 降级时的MaxActive，为0时没有降级
+	errRate        float64 // 错误率的指数加权移动平均
+	lastDials      int64   // 上次checkDegradation时的统计数据
+	lastHits       int64
+	lastErrors     int64
+	healthFailures int64 // 上次checkDegradation之后TestOnBorrow和Ping失败的次数
+
+	probeBackoff int    // 探测连续失败后跳过的次数
+	probeSkip    int    // 还要跳过几次探测
+	probed       int    // 探测临时多占用的active，对象放回时归还
+	checkRound   uint64 // checkIdle的轮次
+
+	started bool            // 后台goroutine是否已启动
+	done    chan struct{}   // Close时关闭，通知后台goroutine退出
+	closeCh chan struct{}   // NewPoolContext创建，第一次Close时关闭
+	bg      sync.WaitGroup  // 后台goroutine
+	ctx     context.Context // NewPoolContext的ctx，被取消时后台goroutine也会退出
 }
 
-type idleObj struct {
-	obj interface{}
-	t   time.Time
+// ConnectionInfo 是pool中对象的信息，空闲列表中保存的就是ConnectionInfo
+type ConnectionInfo struct {
+	Obj       interface{}
+	IdleSince time.Time // 最近一次放回空闲列表的时间
+	CreatedAt time.Time
+	Uses      int    // 被借出的次数
+	Endpoint  string // 创建对象时使用的endpoint，只在设置了NewWithEndpoint时记录
+
+	expires      time.Time // 过期时间，为零值时不会过期
+	borrowedAt   time.Time // 最近一次被借出的时间
+	preemptAfter time.Time // 上次对这个对象调用PreemptCallback的时间
+	owner        int64     // 借出对象的goroutine，只在设置了MaxBorrowsPerGoroutine时记录
+	label        string    // LabeledGet传入的标签，只在借出期间记录
+	reqID        string    // GetWithRequestID传入的请求ID，只在借出期间记录
+
+	tag     interface{}   // 创建时由Tag计算
+	gen     uint64        // 借出时pool的generation
+	version uint64        // 创建时的ConnectionVersion
+	score   float64       // 最近一次HealthScore的结果
+	tier    int           // 放回时由Tier计算
+	limbo   bool          // 是否是从limbo中借出的，放回时丢弃
+	checked uint64        // 最近一次通过checkIdle检查的轮次
+	jitter  time.Duration // 创建时抽取的IdleTimeoutJitter随机值
 }
 
 func NewPool(New func() (interface{}, error), maxIdle int) *Pool {
@@ -42,144 +349,1017 @@ func NewPool(New func() (interface{}, error), maxIdle int) *Pool {
 }
 
 func (p *Pool) Get() (interface{}, error) {
+	return p.GetContext(context.Background())
+}
+
+// GetContext 与Get相同，但在等待可用对象时，ctx被取消会返回ctx.Err()
+func (p *Pool) GetContext(ctx context.Context) (interface{}, error) {
+	return p.get(ctx, getOptions{})
+}
+
+// GetWithRetry 在GetContext失败时最多重试retries次，第一次重试前等待backoff，之后每次等待的时间加倍，
+// 返回第一次成功的结果或最后一次的错误。ctx被取消或pool已关闭时不再重试
+func (p *Pool) GetWithRetry(ctx context.Context, retries int, backoff time.Duration) (interface{}, error) {
+	for i := 0; ; i++ {
+		obj, err := p.GetContext(ctx)
+		if err == nil || i >= retries || ctx.Err() != nil || errors.Is(err, ErrPoolClosed) {
+			return obj, err
+		}
+		t := time.NewTimer(backoff)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return nil, err
+		}
+		backoff *= 2
+	}
+}
+
+// getOptions 是每次Get的参数
+type getOptions struct {
+	priority int // 等待时的优先级，越小越优先
+
+	prefer func(ConnectionInfo) bool // 优先选择满足prefer的空闲对象，如标签相同的
+
+	idleOnly bool // 只借出空闲对象，不创建新对象也不等待
+
+	waitTimeout time.Duration // 不为0时代替Wait和WaitTimeout：大于0时最多等待waitTimeout，小于0时不等待
+
+	label string // 记录在借出记录中，见LabeledGet
+	reqID string // 记录在借出记录和审计日志中，见GetWithRequestID
+}
+
+func (p *Pool) get(ctx context.Context, opts getOptions) (interface{}, error) {
+	if d := poolTimeout(ctx); d > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+	if p.GetRateLimiter != nil && !opts.idleOnly {
+		if err := p.GetRateLimiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if p.MaxConcurrentGet > 0 && !opts.idleOnly {
+		if err := p.enterGet(ctx); err != nil {
+			return nil, err
+		}
+		defer p.leaveGet()
+	}
+
 	p.mu.Lock()
+	p.lazyInit()
+
+	var gid int64 // 只在设置了MaxBorrowsPerGoroutine时获取
+	if p.MaxBorrowsPerGoroutine > 0 {
+		gid = goroutineID()
+		if p.borrows[gid] >= p.MaxBorrowsPerGoroutine {
+			p.mu.Unlock()
+			return nil, p.err(ErrBorrowLimitExceeded)
+		}
+	}
 
-	drop := p.DropCallback
 	// 清除过期的对象
-	if timeout := p.IdleTimeout; timeout > 0 {
-		for i, n := 0, p.idle.Len(); i < n; i++ {
-			e := p.idle.Back() // 最旧的那个
-			if e == nil {
-				break
+	if expired := p.removeExpired(); len(expired) > 0 {
+		p.dropObjs(expired...)
+		p.mu.Lock()
+	}
+
+	var waited time.Duration // 阻塞在wait()中的时间
+	waitCtx := ctx           // 第一次等待时根据WaitTimeout创建
+	var cancel context.CancelFunc
+	fair := false // 设置了FairGet时，被唤醒时分配到了空闲对象
+	var w *waiter // 第一次等待时创建，之后被唤醒再次等待时复用
+
+	// 获取空闲对象
+	for {
+		if (p.paused || p.throttle == 100) && !p.closed {
+			if !p.Wait || opts.idleOnly {
+				err := p.err(ErrPoolPaused)
+				if !p.paused {
+					err = p.err(ErrPoolExhausted)
+				}
+				p.mu.Unlock()
+				return nil, err
+			}
+			if cancel == nil && p.WaitTimeout > 0 {
+				waitCtx, cancel = context.WithTimeout(ctx, p.WaitTimeout)
+				defer cancel()
 			}
-			io := e.Value.(idleObj)
-			if io.t.Add(timeout).After(nowFunc()) {
-				break // 最旧的那个都没有过期，其他的也不会过期
+			start := nowFunc()
+			var err error
+			if w == nil {
+				w = p.newWaiter(opts.priority)
 			}
-			// 清除过期的
-			p.idle.Remove(e)
-			p.release()
-			if drop != nil {
+			fair, err = p.waitTurn(waitCtx, w, nil)
+			waited += nowFunc().Sub(start)
+			if err != nil {
+				err = p.waitErr(ctx, err)
 				p.mu.Unlock()
-				drop(io.obj)
-				p.mu.Lock()
+				return nil, err
 			}
+			continue
 		}
-	}
 
-	// 获取空闲对象
-	for {
-		for i, n := 0, p.idle.Len(); i < n; i++ {
-			e := p.idle.Front() // 最新的
-			if e == nil {
+		if obj, ok := p.borrowShared(); ok {
+			events, observer := p.requestSink(opts.reqID), p.observer
+			p.mu.Unlock()
+			p.stats.hits.Add(1)
+			publish(events, EventBorrowed, obj, nil)
+			if observer != nil {
+				observer.OnGet(obj, true, waited)
+			}
+			return obj, nil
+		}
+
+		if p.prevalidating(fair, opts) {
+			obj, ok := p.prevalidate(gid, fair, opts, waited)
+			if ok {
+				return obj, nil
+			}
+			fair = false
+			continue
+		}
+
+		for i, n := 0, p.idle.Len()+p.limbo.Len(); i < n; i++ {
+			io, ok := p.nextIdle(fair, opts)
+			if !ok {
 				break
 			}
-			io := e.Value.(idleObj)
-			p.idle.Remove(e)
+			fair = false
 
-			test := p.TestOnBorrow
+			test, testWithCount := p.TestOnBorrow, p.TestOnBorrowWithCount
+			retries, retryDelay := p.TestOnBorrowRetries, p.TestOnBorrowRetryDelay
+			multiplex := p.maxUses() > 1
+			events, observer := p.requestSink(opts.reqID), p.observer
+			uses := io.Uses
+			io.Uses++
+			io.owner, io.label, io.reqID = gid, opts.label, opts.reqID
+			io.gen = p.generation
+			p.track(io)
 			p.mu.Unlock()
-			if test == nil || test(io.obj) == nil {
-				return io.obj, nil
+			var err error
+			canceled := false // 重试时ctx被取消
+			for r := 0; ; r++ {
+				if testWithCount != nil {
+					err = testWithCount(io.Obj, uses)
+				} else if test != nil {
+					err = test(io.Obj)
+				}
+				if err == nil || r >= retries {
+					break
+				}
+				if retryDelay > 0 {
+					t := time.NewTimer(retryDelay)
+					select {
+					case <-t.C:
+					case <-ctx.Done():
+						t.Stop()
+					}
+				}
+				if ctx.Err() != nil {
+					canceled = true
+					break
+				}
+			}
+			if err == nil {
+				if multiplex {
+					p.share(io.Obj)
+				}
+				p.stats.hits.Add(1)
+				publish(events, EventBorrowed, io.Obj, nil)
+				if observer != nil {
+					observer.OnGet(io.Obj, true, waited)
+				}
+				return io.Obj, nil
 			}
 			// 这个对象不可用了，丢掉
-			if drop != nil {
-				drop(io.obj)
+			p.mu.Lock()
+			p.healthCheckFailed(io.Obj, "TestOnBorrow", err)
+			if _, ok := p.untrack(io.Obj); ok {
+				p.retire(io)
+			}
+			p.dropObjs(io.Obj)
+			if canceled {
+				return nil, ctx.Err()
 			}
 			p.mu.Lock()
-			p.release()
 		}
 
 		// 在创建新对象前检查是否关闭
 		if p.closed {
+			err := p.err(ErrPoolClosed)
+			p.mu.Unlock()
+			return nil, err
+		}
+		if opts.idleOnly {
 			p.mu.Unlock()
-			return nil, ErrPoolClosed
+			return nil, errNoIdle
 		}
 
-		if p.MaxActive == 0 || p.active < p.MaxActive {
-			newFunc := p.New
+		var groupFull <-chan struct{} // 超出PoolGroup的限制或MaxDialRate时，可以再尝试创建对象时会被关闭
+		exhausted := ErrPoolExhausted // 不能创建对象的原因
+		maxActive := p.maxActive()
+		canDial := maxActive == 0 || p.active < maxActive
+		if canDial {
+			if delay := p.takeDialToken(); delay > 0 { // 超出MaxDialRate时，等到有令牌后重试
+				canDial, groupFull = false, after(delay)
+			}
+		}
+		if canDial {
+			if canDial, groupFull = p.group.acquire(); !canDial {
+				p.returnDialToken()
+				exhausted = ErrGroupFull
+			}
+		}
+		if canDial {
+			dial, endpoint, err := p.dialer()
+			if err != nil {
+				p.group.release(1)
+				p.returnDialToken()
+				p.mu.Unlock()
+				return nil, err
+			}
+			track := p.tracking()
+			gen, version := p.generation, p.ConnectionVersion
+			tagFunc := p.Tag
+			events, observer := p.requestSink(opts.reqID), p.observer
+			multiplex := p.maxUses() > 1
 			p.active++
 			p.mu.Unlock()
-			obj, err := newFunc()
-			if err != nil {
+			obj, err := dial(ctx)
+			var tag interface{}
+			if err == nil && tagFunc != nil {
+				tag = tagFunc(obj)
+			}
+			if err != nil || track {
 				p.mu.Lock()
-				p.release()
+				if err != nil {
+					p.release()
+					p.dialFailed(err)
+					obj = nil
+				} else {
+					if gen != p.generation { // 创建期间调用了Reset，这个对象放回时会被丢弃
+						p.release()
+					}
+					p.track(ConnectionInfo{Obj: obj, Uses: 1, owner: gid, label: opts.label, reqID: opts.reqID, CreatedAt: nowFunc(), Endpoint: endpoint, tag: tag, gen: gen, version: version, jitter: p.idleJitter()})
+				}
 				p.mu.Unlock()
-				obj = nil
+			}
+			p.stats.misses.Add(1)
+			publish(events, EventCreated, obj, err)
+			if err == nil {
+				if multiplex {
+					p.share(obj)
+				}
+				publish(events, EventBorrowed, obj, nil)
+				if observer != nil {
+					observer.OnCreate(obj)
+					observer.OnGet(obj, false, waited)
+				}
 			}
 			return obj, err
 		}
 
-		if !p.Wait { // 不等待
+		p.stats.exhausted.Add(1)
+		p.event(EventExhausted, nil, nil)
+		p.log(slog.LevelWarn, "pool: exhausted", "max_active", maxActive, "waiters", len(p.waiters))
+		wait := p.Wait
+		if opts.waitTimeout != 0 {
+			wait = opts.waitTimeout > 0
+		}
+		if !wait || (p.MaxWaiters > 0 && len(p.waiters) >= p.MaxWaiters) { // 不等待
+			err := p.err(exhausted)
+			if wait {
+				err = p.err(ErrTooManyWaiters)
+			}
+			onExhausted := p.exhaustedCallback()
+			logs := p.takeLogs()
 			p.mu.Unlock()
-			return nil, ErrPoolExhausted
+			emitLogs(logs)
+			if onExhausted != nil {
+				onExhausted()
+			}
+			return nil, err
 		}
 
-		if p.cond == nil {
-			p.cond = sync.NewCond(&p.mu)
+		if timeout := p.WaitTimeout; cancel == nil && (timeout > 0 || opts.waitTimeout > 0) {
+			if opts.waitTimeout > 0 {
+				timeout = opts.waitTimeout
+			}
+			waitCtx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		start := nowFunc()
+		var err error
+		if w == nil {
+			w = p.newWaiter(opts.priority)
+		}
+		fair, err = p.waitTurn(waitCtx, w, groupFull)
+		waited += nowFunc().Sub(start)
+		if err != nil {
+			err = p.waitErr(ctx, err)
+			p.mu.Unlock()
+			return nil, err
 		}
-		p.cond.Wait()
+	}
+}
+
+// nextIdle 从空闲列表中取出下一个要借出的对象：等级最小的对象中按HealthScore、SelectLRU选择，
+// 有opts.prefer时优先选择满足它的；没有空闲对象时取出空闲超过MaxIdleTime的对象，版本过时的对象会被丢弃。
+// fair为false时不会取走已经分配给被唤醒的等待者的对象。
+// 调用时需持有锁，返回时仍然持有锁，没有可以借出的对象时返回false
+func (p *Pool) nextIdle(fair bool, opts getOptions) (ConnectionInfo, bool) {
+	for {
+		if !fair && p.assigned > 0 && p.idle.Len() <= p.assigned { // 空闲对象都分配给了先等待的goroutine
+			return ConnectionInfo{}, false
+		}
+		idle := &p.idle
+		if idle.Len() == 0 { // 没有空闲对象时借出超过MaxIdleTime的对象
+			idle = &p.limbo
+		}
+		e := idle.Front() // 等级最小的对象中最新的
+		if p.SelectLRU {
+			e = idle.TopBack() // 等级最小的对象中空闲最久的
+		}
+		if p.HealthScore != nil && idle == &p.idle {
+			e = idle.Best() // 等级最小的对象中分数最高的
+		}
+		if opts.prefer != nil && idle == &p.idle && !fair {
+			if pe := p.findIdle(opts.prefer); pe != nil {
+				e = pe
+			}
+		}
+		if e == nil {
+			return ConnectionInfo{}, false
+		}
+		io := idle.Remove(e)
+		fair = false
+		if io.version < p.ConnectionVersion { // SetVersion之前创建的对象
+			p.retire(io)
+			p.evict(io.Obj, "old version")
+			p.dropObjs(io.Obj)
+			p.mu.Lock()
+			continue
+		}
+		io.limbo = idle == &p.limbo
+		return io, true
 	}
 }
 
 func (p *Pool) Put(obj interface{}) {
+	p.PutWithTTL(obj, 0)
+}
+
+// PutWithTTL 与Put相同，但对象在空闲列表中最多保存ttl，为0时使用IdleTimeout
+func (p *Pool) PutWithTTL(obj interface{}, ttl time.Duration) {
+	p.put(obj, ttl, false)
+}
+
+// TryPut 与Put相同，但空闲对象已经达到MaxIdle时不会丢弃最旧的空闲对象，而是丢弃obj（调用DropCallback），
+// 返回obj是否放回了空闲列表，如借出期间调小了MaxIdle时返回false。对象被GetShared等共享且还有其他借用者时返回true
+func (p *Pool) TryPut(obj interface{}) bool {
+	return p.put(obj, 0, true)
+}
+
+// put 把对象放回空闲列表，noEvict为true时空闲列表已满则丢弃obj，返回obj是否被放回
+func (p *Pool) put(obj interface{}, ttl time.Duration, noEvict bool) bool {
+	if p.RefillOnDrop {
+		if err := checkClosed(obj); err != nil {
+			p.PutErr(obj, err)
+			return false
+		}
+	}
 	p.mu.Lock()
+	p.endLease(obj)
+	if p.fromOverflow(obj) {
+		observer := p.observer
+		p.dropObjs(obj)
+		if observer != nil {
+			observer.OnPut(obj, true)
+		}
+		return false
+	}
+	if inUse, drop := p.returnShared(obj, false); inUse { // 还有其他借用者在使用
+		p.mu.Unlock()
+		return true
+	} else if drop {
+		p.mu.Unlock()
+		p.PutErr(obj, errSharedBroken)
+		return false
+	}
+	if reset := p.ResetCallback; reset != nil && !p.closed {
+		p.mu.Unlock()
+		if err := reset(obj); err != nil {
+			p.PutErr(obj, err)
+			return false
+		}
+		p.mu.Lock()
+	}
+	tier := 0
+	if tierFunc := p.Tier; tierFunc != nil && !p.closed {
+		p.mu.Unlock()
+		tier = clampTier(tierFunc(obj))
+		p.mu.Lock()
+	}
 
-	if !p.closed {
-		p.idle.PushFront(idleObj{
-			t:   nowFunc(),
-			obj: obj,
-		})
-		if p.idle.Len() > p.MaxIdle {
-			obj = p.idle.Remove(p.idle.Back()).(idleObj).obj
+	observer := p.observer
+	io, ok := p.untrack(obj)
+	if !ok {
+		p.dropObjs(obj) // 不属于当前pool的对象或者Reset之前借出的对象，不改变active
+		if observer != nil {
+			observer.OnPut(obj, true)
+		}
+		return false
+	}
+	put, dropped, reqID := obj, true, io.reqID
+	if !io.limbo && !p.closed && !(noEvict && p.idle.Len() >= p.maxIdle()) && !p.returnProbeSlot() {
+		io.IdleSince = nowFunc()
+		io.tier = tier
+		io.label, io.reqID = "", ""
+		if io.CreatedAt.IsZero() { // 没有记录的对象，只能以第一次放回的时间作为创建时间
+			io.CreatedAt = io.IdleSince
+		}
+		if ttl > 0 {
+			io.expires = io.IdleSince.Add(ttl)
 		} else {
-			if p.cond != nil {
-				p.cond.Signal()
-			}
+			p.setExpires(&io)
+		}
+		e := p.idle.PushFront(io)
+		publish(p.requestSink(reqID), EventReturned, obj, nil)
+		if p.idle.Len() > p.maxIdle() {
+			back := p.idle.Back()
+			dropped = back == e
+			io = p.idle.Remove(back)
+			obj = io.Obj
+			p.evict(obj, "max idle")
+			p.recycle(io)
+		} else {
+			p.signalIdle()
 			p.mu.Unlock()
-			return
+			if observer != nil {
+				observer.OnPut(put, false)
+				observeRequest(observer, put, reqID, true)
+			}
+			return true
 		}
 	}
 
-	p.release()
-	drop := p.DropCallback
-	p.mu.Unlock()
-	if drop != nil {
-		drop(obj)
+	p.retire(io)
+	p.dropObjs(obj)
+	if observer != nil {
+		observer.OnPut(put, dropped)
+		observeRequest(observer, put, reqID, true)
+	}
+	return !dropped
+}
+
+// PutErr 在err不为nil时丢弃对象，否则与Put相同
+func (p *Pool) PutErr(obj interface{}, err error) {
+	if err == nil {
+		p.Put(obj)
+		return
+	}
+	p.mu.Lock()
+	p.endLease(obj)
+	if inUse, _ := p.returnShared(obj, true); inUse {
+		p.mu.Unlock()
+		return
+	}
+	var reqID string
+	if !p.fromOverflow(obj) {
+		if io, ok := p.untrack(obj); ok {
+			reqID = io.reqID
+			p.retire(io)
+		}
+	}
+	observer := p.observer
+	if p.RefillOnDrop && !p.closed && p.idle.Len() < p.MinIdle {
+		p.goRefill()
+	}
+	p.dropObjs(obj)
+	if observer != nil {
+		observer.OnPut(obj, true)
+		observeRequest(observer, obj, reqID, true)
+	}
+}
+
+// Stagger 在新的goroutine中依次Put(objs)中的对象，每两个之间间隔d，避免一次放回大量对象后
+// 下次Get()时同时检查、同时重新创建。返回的channel在所有对象都放回后关闭
+func (p *Pool) Stagger(objs []interface{}, d time.Duration) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i, obj := range objs {
+			if i > 0 {
+				time.Sleep(d)
+			}
+			p.Put(obj)
+		}
+	}()
+	return done
+}
+
+// WithResource 获取一个对象并调用fn，结束后归还对象，fn返回错误时对象会被丢弃
+func (p *Pool) WithResource(ctx context.Context, fn func(interface{}) error) error {
+	obj, err := p.GetContext(ctx)
+	if err != nil {
+		return err
 	}
-	return
+	err = fn(obj)
+	p.PutErr(obj, err)
+	return err
+}
+
+// Do 获取一个对象并调用fn，然后调用PutErr(obj, err)，返回Get()或fn的错误
+func (p *Pool) Do(fn func(interface{}) error) error {
+	return p.WithResource(context.Background(), fn)
+}
+
+// DoContext 与Do相同，但使用GetContext(ctx)获取对象
+func (p *Pool) DoContext(ctx context.Context, fn func(interface{}) error) error {
+	return p.WithResource(ctx, fn)
+}
+
+// Acquire 与Get相同，但同时返回归还对象的release，可以直接defer release()。
+// 出错时release为nil，release被多次调用时只有第一次会Put
+func (p *Pool) Acquire() (interface{}, func(), error) {
+	obj, err := p.Get()
+	if err != nil {
+		return nil, nil, err
+	}
+	var once sync.Once
+	return obj, func() { once.Do(func() { p.Put(obj) }) }, nil
+}
+
+// GetIfAvailable 只在有空闲对象时借出对象，不会创建新对象也不会等待；没有空闲对象或pool已关闭时返回nil, false
+func (p *Pool) GetIfAvailable() (interface{}, bool) {
+	obj, err := p.get(context.Background(), getOptions{idleOnly: true})
+	return obj, err == nil
 }
 
+// ActiveCount 返回借出的对象数，设置了ShareReadOnly时共享的只读对象按借用者的数量计算
 func (p *Pool) ActiveCount() int {
 	p.mu.Lock()
-	active := p.active
+	active := p.active + p.sharedRefs
 	p.mu.Unlock()
 	return active
 }
 
+// IdleCount 返回空闲对象的数量
+func (p *Pool) IdleCount() int {
+	p.mu.Lock()
+	idle := p.idle.Len()
+	p.mu.Unlock()
+	return idle
+}
+
 func (p *Pool) Close() {
+	p.doClose()
+}
+
+// OnceClose 与Close相同，但只有第一次调用会执行关闭的逻辑，之后的调用等第一次完成后直接返回，
+// 用于多个goroutine可能同时关闭pool的情况
+func (p *Pool) OnceClose() {
+	p.closeOnce.Do(p.doClose)
+}
+
+// doClose 关闭pool并丢弃所有空闲对象，多次调用时后面的调用只会等待后台goroutine退出
+func (p *Pool) doClose() {
 	p.mu.Lock()
-	idle := p.idle
-	p.idle.Init()
+	if p.done != nil {
+		close(p.done)
+		p.done = nil
+	}
+	if p.closeCh != nil && !p.closed {
+		close(p.closeCh)
+	}
+	objs := p.takeIdle()
 	p.closed = true
-	p.active -= idle.Len()
-	if p.cond != nil {
-		p.cond.Broadcast()
+	p.active -= len(objs)
+	p.group.release(len(objs))
+	p.broadcast()
+	p.mu.Unlock()
+	p.bg.Wait() // 等后台goroutine退出后再丢弃对象
+
+	p.mu.Lock()
+	p.dropObjs(objs...)
+}
+
+// Pause 暂停pool，之后的Get()会阻塞(Wait为true时)或返回ErrPoolPaused，不影响Put()和空闲对象
+func (p *Pool) Pause() {
+	p.mu.Lock()
+	p.paused = true
+	p.mu.Unlock()
+}
+
+// Resume 恢复被暂停的pool，并唤醒所有等待的goroutine
+func (p *Pool) Resume() {
+	p.mu.Lock()
+	p.paused = false
+	p.broadcast()
+	p.mu.Unlock()
+}
+
+func (p *Pool) IsPaused() bool {
+	p.mu.Lock()
+	paused := p.paused
+	p.mu.Unlock()
+	return paused
+}
+
+// lazyInit 在第一次Get()时启动后台goroutine，调用时需持有锁
+func (p *Pool) lazyInit() {
+	if p.started || p.closed {
+		return
+	}
+	p.started = true
+	p.done = make(chan struct{})
+	if p.AutoScale && p.AutoScaleInterval > 0 {
+		p.goBackground(p.AutoScaleInterval, p.autoScale)
+	}
+	if p.JanitorInterval > 0 {
+		p.goBackground(p.JanitorInterval, p.janitor)
+	}
+	if p.KeepaliveInterval > 0 && p.Ping != nil {
+		p.goBackground(p.KeepaliveInterval, p.keepalive)
+	}
+	if p.HealthCheckInterval > 0 && p.TestOnBorrow != nil {
+		p.goBackground(p.HealthCheckInterval, p.checkIdleHealth)
+	}
+	if p.warming != nil {
+		p.goWarming(p.warming)
+	}
+	if p.PreemptTimeout > 0 && p.PreemptCallback != nil {
+		p.goBackground(p.PreemptTimeout, p.preempt)
+	}
+	if p.ExhaustionProbeInterval > 0 {
+		p.goBackground(p.ExhaustionProbeInterval, p.probeExhausted)
+	}
+	if p.SlowStartInitial > 0 && p.SlowStartInterval > 0 && p.MaxActive > 0 {
+		p.slowStarting = true
+		p.slowStartActive = p.SlowStartInitial
+		p.goBackground(p.SlowStartInterval, p.slowStart)
+	}
+}
+
+// goBackground 启动一个每隔interval调用一次f的后台goroutine
+func (p *Pool) goBackground(interval time.Duration, f func()) {
+	done := p.done
+	var ctxDone <-chan struct{}
+	if p.ctx != nil {
+		ctxDone = p.ctx.Done()
+	}
+	p.bg.Add(1)
+	go func() {
+		defer p.bg.Done()
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctxDone:
+				return
+			case <-t.C:
+				f()
+			}
+		}
+	}()
+}
+
+// tracking 返回是否需要记录借出的对象，以便放回时保留对象的信息。
+// 记录保存在以对象为key的map中，开启这些选项时对象需要能作为map的key（文档中都有说明）
+func (p *Pool) tracking() bool {
+	return p.TrackActive || p.TrackState || p.TestOnBorrowWithCount != nil || p.MaxBorrowsPerGoroutine > 0 || p.Tag != nil || p.NewWithEndpoint != nil ||
+		p.ConnectionVersion > 0 || p.HealthScore != nil || p.MaxIdleTime > 0 || p.IdleTimeoutJitter > 0
+}
+
+// track 记录借出的对象，调用时需持有锁
+func (p *Pool) track(io ConnectionInfo) {
+	if !p.tracking() {
+		return
+	}
+	if p.borrowed == nil {
+		p.borrowed = make(map[interface{}]ConnectionInfo)
+	}
+	io.borrowedAt = nowFunc()
+	p.borrowed[io.Obj] = io
+	if io.owner != 0 {
+		if p.borrows == nil {
+			p.borrows = make(map[int64]int)
+		}
+		p.borrows[io.owner]++
+	}
+}
+
+// untrack 取出借出对象的记录，没有记录时返回一个新的记录。
+// 对象不计入active时返回false：开启TrackActive时没有记录，说明对象不是当前pool借出的；
+// 或者对象是Reset之前借出的。
+// 调用时需持有锁
+func (p *Pool) untrack(obj interface{}) (ConnectionInfo, bool) {
+	if len(p.borrowed) == 0 { // 避免对象不能作为map的key时panic
+		return ConnectionInfo{Obj: obj}, !p.TrackActive
+	}
+	io, ok := p.borrowed[obj]
+	if !ok {
+		return ConnectionInfo{Obj: obj}, !p.TrackActive
+	}
+	delete(p.borrowed, obj)
+	if io.owner != 0 {
+		if p.borrows[io.owner]--; p.borrows[io.owner] <= 0 {
+			delete(p.borrows, io.owner)
+		}
+		io.owner = 0
+	}
+	return io, io.gen == p.generation // Reset之前借出的对象已经不计入active
+}
+
+// takeIdle 移除并返回所有空闲对象（包括limbo中的），调用时需持有锁
+func (p *Pool) takeIdle() []interface{} {
+	objs := make([]interface{}, 0, p.idle.Len()+p.limbo.Len())
+	for _, l := range []*idleList{&p.idle, &p.limbo} {
+		for e := l.Front(); e != nil; e = e.Next() {
+			io := e.Value
+			p.recordLifetime(io.CreatedAt)
+			objs = append(objs, io.Obj)
+		}
+		l.Init()
+	}
+	return objs
+}
+
+// dropObjs 调用DropCallback丢弃objs，调用时需持有锁，返回时会释放锁
+func (p *Pool) dropObjs(objs ...interface{}) {
+	// 过期或超出MaxIdle的空闲对象调用Recycle代替DropCallback，它们没有被销毁
+	var recycled []ConnectionInfo
+	if len(p.recycling) > 0 {
+		destroyed := objs[:0:0]
+		for _, obj := range objs {
+			if io, ok := p.recycling[obj]; ok {
+				recycled = append(recycled, io)
+			} else {
+				destroyed = append(destroyed, obj)
+			}
+		}
+		objs = destroyed
+	}
+	recycle := p.Recycle
+	p.recycling = nil
+	for _, obj := range objs {
+		p.event(EventDestroyed, obj, nil)
+	}
+	if p.meta != nil {
+		for _, obj := range objs {
+			delete(p.meta, obj)
+		}
+		for _, io := range recycled {
+			delete(p.meta, io.Obj)
+		}
 	}
 	drop := p.DropCallback
+	observer := p.observer
+	trackState := p.TrackState && len(objs) > 0
+	if trackState {
+		if p.closing == nil {
+			p.closing = make(map[interface{}]int)
+		}
+		for _, obj := range objs {
+			p.closing[obj]++
+		}
+	}
+	logs := p.takeLogs()
 	p.mu.Unlock()
+	emitLogs(logs)
+
+	var reused []ConnectionInfo
+	for _, io := range recycled {
+		if r := recycle(io.Obj); r != nil {
+			reused = append(reused, ConnectionInfo{Obj: r, CreatedAt: io.CreatedAt, Uses: io.Uses, Endpoint: io.Endpoint, version: io.version, jitter: io.jitter})
+		}
+	}
+	if len(reused) > 0 {
+		p.importIdle(reused, true)
+	}
+	if drop != nil {
+		for _, obj := range objs {
+			drop(obj)
+		}
+	}
+	if observer != nil {
+		for _, obj := range objs {
+			observer.OnDestroy(obj)
+		}
+	}
+
+	if trackState {
+		p.mu.Lock()
+		for _, obj := range objs {
+			if p.closing[obj]--; p.closing[obj] <= 0 {
+				delete(p.closing, obj)
+			}
+		}
+		p.mu.Unlock()
+	}
+}
+
+// exhaustedCallback 返回这次需要调用的OnExhausted，被限流时返回nil，调用时需持有锁
+func (p *Pool) exhaustedCallback() func() {
+	if p.OnExhausted == nil {
+		return nil
+	}
+	now := nowFunc()
+	if !p.lastExhausted.IsZero() && now.Sub(p.lastExhausted) < p.OnExhaustedThrottle {
+		return nil
+	}
+	p.lastExhausted = now
+	return p.OnExhausted
+}
 
-	if drop == nil {
+// maxActive 返回当前有效的MaxActive，慢启动阶段返回slowStartActive，
+// 有ReduceMaxActive、Throttle或降级时不超过其中最小的上限，调用时需持有锁
+func (p *Pool) maxActive() int {
+	max := p.MaxActive
+	if p.slowStarting && p.slowStartActive < max {
+		max = p.slowStartActive
+	}
+	if p.degradeMax > 0 && p.degradeMax < max {
+		max = p.degradeMax
+	}
+	if p.throttle > 0 && p.MaxActive > 0 {
+		if t := p.MaxActive * (100 - p.throttle) / 100; t < max {
+			max = t
+		}
+		if max < 1 {
+			max = 1
+		}
+	}
+	for _, c := range p.activeCaps {
+		if max == 0 || c < max {
+			max = c
+		}
+	}
+	return max
+}
+
+// maxIdle 返回空闲对象的上限，调用时需持有锁
+func (p *Pool) maxIdle() int {
+	if p.MaxIdlePercent > 0 && p.MaxActive > 0 {
+		return int(p.MaxIdlePercent * float64(p.MaxActive))
+	}
+	return p.MaxIdle
+}
+
+// idleJitter 为新创建的对象抽取[0, IdleTimeoutJitter)的随机值，之后每次放回都使用这个值
+func (p *Pool) idleJitter() time.Duration {
+	if p.IdleTimeoutJitter <= 0 {
+		return 0
+	}
+	return time.Duration(randInt63n(int64(p.IdleTimeoutJitter)))
+}
+
+// setExpires 根据io.IdleSince和创建时抽取的jitter设置过期时间，调用时需持有锁
+func (p *Pool) setExpires(io *ConnectionInfo) {
+	if p.IdleTimeout <= 0 {
+		io.expires = time.Time{}
 		return
 	}
-	for e := idle.Front(); e != nil; e = e.Next() {
-		drop(e.Value.(idleObj).obj)
+	if io.jitter == 0 { // 没有记录的对象，在第一次放回时抽取
+		io.jitter = p.idleJitter()
+	}
+	io.expires = io.IdleSince.Add(p.IdleTimeout + io.jitter)
+}
+
+// removeExpired 从空闲列表中移除过期的对象并返回它们，空闲超过MaxIdleTime的对象会被移到limbo，调用时需持有锁。
+// 只查看按过期时间和IdleSince排列的堆顶，没有对象过期时不需要遍历空闲列表
+func (p *Pool) removeExpired() []interface{} {
+	var expired []interface{}
+	now := nowFunc()
+	for _, l := range []*idleList{&p.idle, &p.limbo} {
+		for e := l.Expiring(); e != nil && !e.Value.expires.After(now); e = l.Expiring() {
+			io := l.Remove(e)
+			p.retire(io)
+			p.evict(io.Obj, "expired")
+			p.recycle(io)
+			expired = append(expired, io.Obj)
+		}
+	}
+	if p.MaxIdleTime > 0 {
+		for e := p.idle.Oldest(); e != nil && now.Sub(e.Value.IdleSince) >= p.MaxIdleTime; e = p.idle.Oldest() {
+			p.limbo.PushFront(p.idle.Remove(e))
+		}
 	}
+	return expired
+}
+
+// janitor 在后台清除过期的空闲对象
+func (p *Pool) janitor() {
+	p.mu.Lock()
+	leaked := p.reclaimBorrowed()
+	onExceeded := p.OnBorrowDeadlineExceeded
+	objs := p.removeExpired()
+	expired := len(objs)
+	if p.ShrinkPolicy != nil && !p.closed {
+		n := p.ShrinkPolicy.ShouldShrink(p.idle.Len(), p.active, p.maxIdle())
+		objs = append(objs, p.evictOldest(n)...)
+	}
+	if len(objs) > 0 || len(leaked) > 0 {
+		p.log(slog.LevelInfo, "pool: janitor sweep", "expired", expired, "shrunk", len(objs)-expired, "reclaimed", len(leaked))
+	}
+	p.checkDegradation()
+	p.dropObjs(objs...)
+	p.scoreIdle()
+
+	if onExceeded != nil {
+		for _, obj := range leaked {
+			onExceeded(obj)
+		}
+	}
+}
+
+// Reset 丢弃所有空闲对象并重置计数和统计数据，但不关闭pool。
+// 有借出记录的对象（开启TrackActive等选项时）不再计入active，之后放回时会被直接丢弃，
+// 正在创建的对象也一样；没有记录的对象pool无法识别，它们仍然计入active
+func (p *Pool) Reset() {
+	p.mu.Lock()
+	objs := p.takeIdle()
+	p.active -= len(objs)
+	p.group.release(len(objs))
+	p.bumpGeneration()
+	p.stats.reset()
+	p.lastErr, p.lastErrAt = nil, time.Time{}
+	p.errBuf, p.errNext, p.errCount = nil, 0, 0
+	p.lifetimes, p.lifeNext, p.lifeCount = nil, 0, 0
+	p.lastDials, p.lastHits, p.lastErrors, p.healthFailures = 0, 0, 0, 0
+	p.degradeMax, p.errRate = 0, 0
+	p.broadcast()
+	p.dropObjs(objs...)
+}
+
+// bumpGeneration 让之前借出的有记录的对象和正在创建的对象过期，它们不再计入active，放回时会被丢弃，调用时需持有锁
+func (p *Pool) bumpGeneration() {
+	stale := 0
+	for _, io := range p.borrowed {
+		if io.gen == p.generation {
+			stale++
+		}
+	}
+	p.generation++
+	p.active -= stale
+	p.group.release(stale)
+}
+
+// dialer 返回创建对象的函数和使用的endpoint，New、NewContext和NewWithEndpoint必须设置且只能设置一个，调用时需持有锁
+func (p *Pool) dialer() (func(context.Context) (interface{}, error), string, error) {
+	newFunc, newContext, newWithEndpoint := p.New, p.NewContext, p.NewWithEndpoint
+	if !p.hasNewFunc() {
+		return nil, "", p.err(errNewFunc)
+	}
+	var endpoint string
+	dial := newContext
+	switch {
+	case newWithEndpoint != nil:
+		if len(p.Endpoints) == 0 {
+			return nil, "", p.err(errNoEndpoints)
+		}
+		endpoint = p.Endpoints[int((p.nextEndpoint.Add(1)-1)%int64(len(p.Endpoints)))]
+		dial = func(context.Context) (interface{}, error) {
+			return newWithEndpoint(endpoint)
+		}
+	case newFunc != nil:
+		dial = func(context.Context) (interface{}, error) {
+			return newFunc()
+		}
+	}
+	if p.testHook != nil {
+		p.dials++
+		dial = p.testHook.wrap(dial, p.dials)
+	}
+	dial = p.countDial(dial)
+	if p.maxDials() > 0 { // 在detachDial里面，调用者放弃后仍然占用名额直到New返回
+		dial = p.limitDial(dial)
+	}
+	dial = detachDial(dial, p.DropCallback) // 调用者放弃后不再占用active
+	if p.DialTimeout > 0 {
+		dial = p.withDialTimeout(dial)
+	}
+	return dial, endpoint, nil
+}
+
+// hasNewFunc 返回New、NewContext和NewWithEndpoint是否只设置了一个，调用时需持有锁
+func (p *Pool) hasNewFunc() bool {
+	n := 0
+	for _, set := range []bool{p.New != nil, p.NewContext != nil, p.NewWithEndpoint != nil} {
+		if set {
+			n++
+		}
+	}
+	return n == 1
 }
 
 func (p *Pool) release() {
 	p.active--
-	if p.cond != nil {
-		p.cond.Signal()
-	}
+	p.group.release(1)
+	p.signal()
 }
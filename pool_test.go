@@ -1,12 +1,18 @@
 package pool
 
 import (
+	"container/list"
+	"context"
 	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
 	"testing"
 	"time"
 )
 
 type conn struct {
+	id int // 让每个对象的地址都不同
 }
 
 type poolDialer struct {
@@ -18,7 +24,7 @@ type poolDialer struct {
 func (d *poolDialer) dial() (interface{}, error) {
 	d.dialed++
 	d.open++
-	return &conn{}, nil
+	return &conn{id: d.dialed}, nil
 }
 
 func (d *poolDialer) drop(interface{}) {
@@ -112,6 +118,141 @@ func TestPoolClose(t *testing.T) {
 	}
 }
 
+func TestPoolTryPut(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+	defer p.Close()
+
+	o1, _ := p.Get()
+	o2, _ := p.Get()
+	o3, _ := p.Get()
+	if !p.TryPut(o1) || !p.TryPut(o2) {
+		t.Error("TryPut()=false, want true")
+	}
+	if p.TryPut(o3) { // 空闲列表已满时丢弃o3而不是最旧的o1
+		t.Error("TryPut() with a full idle list returned true")
+	}
+	d.check("after TryPut", p, 3, 2)
+	if o, _ := p.Get(); o != o2 {
+		t.Errorf("Get()=%v, want %v", o, o2)
+	}
+	if o, _ := p.Get(); o != o1 {
+		t.Errorf("Get()=%v, want %v", o, o1)
+	}
+	p.Put(o1)
+	p.Put(o2)
+}
+
+func TestPoolOnceClose(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+
+	o1, _ := p.Get()
+	o2, _ := p.Get()
+	p.Put(o1)
+	p.Put(o2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.OnceClose()
+		}()
+	}
+	wg.Wait()
+	d.check("after once close", p, 2, 0)
+	if _, err := p.Get(); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("Get()=%v after OnceClose, want ErrPoolClosed", err)
+	}
+}
+
+func TestPoolReset(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+	p.TrackActive = true
+
+	o1, _ := p.Get()
+	o2, _ := p.Get()
+	p.Put(o1)
+	d.check("before reset", p, 2, 2)
+
+	p.Reset()
+	if active := p.ActiveCount(); active != 0 {
+		t.Errorf("active=%d, want 0", active)
+	}
+	p.Put(o2)
+	if d.open != 0 {
+		t.Errorf("open=%d, want 0", d.open)
+	}
+
+	o, err := p.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Put(o)
+	d.check("after reset", p, 3, 1)
+	p.Close()
+}
+
+func TestPoolPutAfterReset(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+	p.TrackState = true // 只要记录了借出对象，Reset之后放回的对象就会被丢弃
+
+	o1, _ := p.Get()
+	o2, _ := p.Get()
+	p.Reset()
+	o3, _ := p.Get()
+	p.Put(o1)
+	p.PutErr(o2, errors.New("broken"))
+	d.check("stale put", p, 3, 1)
+	p.Put(o3)
+	if n := p.IdleCount(); n != 1 {
+		t.Errorf("IdleCount()=%d, want 1", n)
+	}
+	p.Close()
+	d.check("after close", p, 3, 0)
+}
+
+func TestPoolResetDuringDial(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(nil, 2)
+	p.DropCallback = d.drop
+	p.TrackActive = true
+
+	dialing, resume := make(chan struct{}), make(chan struct{})
+	p.New = func() (interface{}, error) {
+		close(dialing)
+		<-resume
+		return d.dial()
+	}
+	got := make(chan interface{})
+	go func() {
+		o, _ := p.Get()
+		got <- o
+	}()
+	<-dialing
+	p.Reset()
+	close(resume)
+	o := <-got
+	if active := p.ActiveCount(); active != 0 {
+		t.Errorf("active=%d, want 0", active)
+	}
+	p.Put(o) // Reset之前开始创建的对象会被丢弃
+	if d.open != 0 {
+		t.Errorf("open=%d, want 0", d.open)
+	}
+	if n := p.IdleCount(); n != 0 {
+		t.Errorf("IdleCount()=%d, want 0", n)
+	}
+	p.Close()
+}
+
 func TestPoolTimeout(t *testing.T) {
 	d := &poolDialer{t: t}
 	p := NewPool(d.dial, 2)
@@ -144,6 +285,95 @@ func TestPoolTimeout(t *testing.T) {
 	p.Close()
 }
 
+func TestPoolResetCallback(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+	resets := 0
+	p.ResetCallback = func(interface{}) error {
+		resets++
+		if resets > 1 {
+			return errors.New("reset failed")
+		}
+		return nil
+	}
+
+	o, _ := p.Get()
+	p.Put(o)
+	d.check("reset ok", p, 1, 1)
+
+	o, _ = p.Get()
+	p.Put(o)
+	d.check("reset failed", p, 1, 0)
+	p.Close()
+}
+
+func TestPoolMaxBorrowsPerGoroutine(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+	p.MaxBorrowsPerGoroutine = 2
+
+	o1, _ := p.Get()
+	o2, _ := p.Get()
+	if _, err := p.Get(); err != ErrBorrowLimitExceeded {
+		t.Errorf("err=%v, want %v", err, ErrBorrowLimitExceeded)
+	}
+
+	done := make(chan error)
+	go func() {
+		o, err := p.Get()
+		if err == nil {
+			p.Put(o)
+		}
+		done <- err
+	}()
+	if err := <-done; err != nil {
+		t.Errorf("other goroutine: %v", err)
+	}
+
+	p.Put(o1)
+	o1, err := p.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Put(o1)
+	p.Put(o2)
+	d.check("done", p, 3, 2)
+	p.Close()
+}
+
+func TestPoolSelectLRU(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.SelectLRU = true
+	defer p.Close()
+
+	o1, _ := p.Get()
+	o2, _ := p.Get()
+	p.Put(o1)
+	p.Put(o2)
+
+	for _, want := range []interface{}{o1, o2, o1} {
+		o, _ := p.Get()
+		if o != want {
+			t.Errorf("got %p, want %p", o, want)
+		}
+		p.Put(o)
+	}
+
+	// 空闲最久的检查失败时，使用下一个
+	p.TestOnBorrow = func(o interface{}) error {
+		if o == o2 {
+			return errors.New("bad")
+		}
+		return nil
+	}
+	if o, _ := p.Get(); o != o1 {
+		t.Errorf("got %p, want %p", o, o1)
+	}
+}
+
 func TestPoolBorrowCheck(t *testing.T) {
 	d := &poolDialer{t: t}
 	p := NewPool(d.dial, 2)
@@ -164,6 +394,79 @@ func TestPoolBorrowCheck(t *testing.T) {
 	p.Close()
 }
 
+func TestPoolBorrowCheckWithCount(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+	var counts []int
+	p.TestOnBorrowWithCount = func(o interface{}, uses int) error {
+		counts = append(counts, uses)
+		if uses >= 3 {
+			return errors.New("too many uses")
+		}
+		return nil
+	}
+
+	for i := 0; i < 5; i++ {
+		o, err := p.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.Put(o)
+	}
+
+	want := []int{1, 2, 3, 1}
+	if fmt.Sprint(counts) != fmt.Sprint(want) {
+		t.Errorf("uses=%v, want %v", counts, want)
+	}
+	d.check("1", p, 2, 1)
+	p.Close()
+}
+
+func TestPoolBorrowCheckRetries(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+	p.TestOnBorrowRetries = 2
+	p.TestOnBorrowRetryDelay = time.Millisecond
+	failures, calls := 0, 0
+	p.TestOnBorrow = func(interface{}) error {
+		calls++
+		if failures > 0 {
+			failures--
+			return errors.New("ping timeout")
+		}
+		return nil
+	}
+
+	o, _ := p.Get()
+	p.Put(o)
+	failures = 2 // 第3次检查成功
+	if o2, _ := p.Get(); o2 != o {
+		t.Errorf("Get()=%v, want %v", o2, o)
+	}
+	p.Put(o)
+	d.check("retry succeeded", p, 1, 1)
+
+	failures, calls = 3, 0 // 重试都失败
+	o, _ = p.Get()
+	if calls != 3 {
+		t.Errorf("calls=%d, want 3", calls)
+	}
+	d.check("retry failed", p, 2, 1)
+	p.Put(o)
+
+	p.TestOnBorrowRetryDelay = time.Second
+	failures = 1
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := p.GetContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("err=%v, want %v", err, context.DeadlineExceeded)
+	}
+	d.check("canceled", p, 2, 0)
+	p.Close()
+}
+
 func TestPoolMaxActive(t *testing.T) {
 	d := &poolDialer{t: t}
 	p := NewPool(d.dial, 2)
@@ -192,6 +495,42 @@ func TestPoolMaxActive(t *testing.T) {
 	p.Close()
 }
 
+func TestPoolOnExhausted(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 1)
+	p.MaxActive = 1
+	p.OnExhaustedThrottle = time.Minute
+	calls := 0
+	p.OnExhausted = func() {
+		calls++
+		if _, err := p.Get(); err != ErrPoolExhausted { // 调用时没有持有锁
+			t.Errorf("err=%v, want %v", err, ErrPoolExhausted)
+		}
+	}
+	defer p.Close()
+
+	now := time.Now()
+	nowFunc = func() time.Time {
+		return now
+	}
+	defer func() {
+		nowFunc = time.Now
+	}()
+
+	o, _ := p.Get()
+	p.Get()
+	p.Get()
+	if calls != 1 {
+		t.Errorf("calls=%d, want 1", calls)
+	}
+	now = now.Add(time.Minute)
+	p.Get()
+	if calls != 2 {
+		t.Errorf("calls=%d, want 2", calls)
+	}
+	p.Put(o)
+}
+
 func startGroutines(p *Pool) chan error {
 	errs := make(chan error, 10)
 	for i := 0; i < 10; i++ {
@@ -274,6 +613,345 @@ func TestWaitPoolClose(t *testing.T) {
 	d.check("done", p, 1, 0)
 }
 
+func TestPoolWithResource(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+	defer p.Close()
+
+	err := p.WithResource(context.Background(), func(o interface{}) error {
+		if o == nil {
+			t.Error("nil object")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.check("1", p, 1, 1)
+
+	fnErr := errors.New("fn err")
+	err = p.WithResource(context.Background(), func(interface{}) error {
+		return fnErr
+	})
+	if err != fnErr {
+		t.Errorf("err=%v, want %v", err, fnErr)
+	}
+	d.check("2", p, 1, 0)
+}
+
+func TestPoolGetIfAvailable(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+
+	if o, ok := p.GetIfAvailable(); ok || o != nil {
+		t.Errorf("GetIfAvailable()=%v, %t, want nil, false", o, ok)
+	}
+	d.check("empty", p, 0, 0)
+
+	o, _ := p.Get()
+	p.Put(o)
+	if o2, ok := p.GetIfAvailable(); !ok || o2 != o {
+		t.Errorf("GetIfAvailable()=%v, %t, want %v, true", o2, ok, o)
+	}
+	p.Put(o)
+
+	p.Close()
+	if _, ok := p.GetIfAvailable(); ok {
+		t.Error("GetIfAvailable() after close")
+	}
+	d.check("after close", p, 1, 0)
+}
+
+func TestPoolStagger(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 3)
+	defer p.Close()
+
+	var objs []interface{}
+	for i := 0; i < 3; i++ {
+		o, _ := p.Get()
+		objs = append(objs, o)
+	}
+	start := time.Now()
+	done := p.Stagger(objs, 10*time.Millisecond)
+	if n := p.IdleCount(); n > 1 {
+		t.Errorf("IdleCount()=%d right after Stagger(), want at most 1", n)
+	}
+	<-done
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("Stagger() took %v, want >= 20ms", elapsed)
+	}
+	if n := p.IdleCount(); n != 3 {
+		t.Errorf("IdleCount()=%d, want 3", n)
+	}
+}
+
+func TestPoolGetWithRetry(t *testing.T) {
+	failures := 2
+	p := &Pool{
+		MaxIdle: 1,
+		New: func() (interface{}, error) {
+			if failures > 0 {
+				failures--
+				return nil, errors.New("dial failed")
+			}
+			return &conn{}, nil
+		},
+	}
+	defer p.Close()
+
+	if _, err := p.GetWithRetry(context.Background(), 1, time.Millisecond); err == nil || err.Error() != "dial failed" {
+		t.Errorf("GetWithRetry(1)=%v, want dial failed", err)
+	}
+	failures = 2
+	start := time.Now()
+	o, err := p.GetWithRetry(context.Background(), 2, 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("GetWithRetry(2)=%v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond { // 5ms + 10ms
+		t.Errorf("elapsed %v, want >= 15ms", elapsed)
+	}
+	p.Put(o)
+	p.Drain()
+
+	failures = 1 // ctx被取消后不再重试
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := p.GetWithRetry(ctx, 3, time.Millisecond); err == nil {
+		t.Error("GetWithRetry() with a canceled ctx succeeded")
+	}
+}
+
+func TestPoolAcquire(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+
+	o, release, err := p.Acquire()
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	release() // 第二次调用不会再归还
+	if n := p.IdleCount(); n != 1 {
+		t.Errorf("IdleCount()=%d, want 1", n)
+	}
+	if o2, _ := p.Get(); o2 != o {
+		t.Errorf("Get()=%v, want %v", o2, o)
+	}
+	p.Put(o)
+
+	p.Close()
+	if _, release, err := p.Acquire(); err != ErrPoolClosed || release != nil {
+		t.Errorf("Acquire() after close: release=%v, err=%v", release != nil, err)
+	}
+	d.check("acquire", p, 1, 0)
+}
+
+func TestPoolDo(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+
+	if err := p.Do(func(interface{}) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	fnErr := errors.New("fn err")
+	if err := p.Do(func(interface{}) error { return fnErr }); err != fnErr {
+		t.Errorf("err=%v, want %v", err, fnErr)
+	}
+	d.check("after do", p, 1, 0)
+
+	p.Close()
+	if err := p.DoContext(context.Background(), func(interface{}) error { return nil }); err != ErrPoolClosed {
+		t.Errorf("err=%v, want %v", err, ErrPoolClosed)
+	}
+}
+
+func TestWaitPoolMaxWaiters(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := &Pool{
+		New:        d.dial,
+		MaxIdle:    1,
+		MaxActive:  1,
+		Wait:       true,
+		MaxWaiters: 1,
+	}
+	defer p.Close()
+
+	o, _ := p.Get()
+	got := make(chan error, 1)
+	go func() {
+		o, err := p.Get()
+		if err == nil {
+			p.Put(o)
+		}
+		got <- err
+	}()
+	deadline := time.Now().Add(2 * time.Second)
+	for p.WaiterCount() != 1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, err := p.Get(); !errors.Is(err, ErrPoolExhausted) {
+		t.Errorf("err=%v, want %v", err, ErrPoolExhausted)
+	}
+	p.Put(o)
+	if err := <-got; err != nil {
+		t.Fatal(err)
+	}
+	d.check("done", p, 1, 1)
+}
+
+func TestPoolNewContext(t *testing.T) {
+	type key struct{}
+	p := &Pool{
+		NewContext: func(ctx context.Context) (interface{}, error) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			return ctx.Value(key{}), nil
+		},
+		MaxIdle: 1,
+	}
+	defer p.Close()
+
+	ctx := context.WithValue(context.Background(), key{}, "v")
+	o, err := p.GetContext(ctx)
+	if err != nil || o != "v" {
+		t.Fatalf("got %v, %v, want v, nil", o, err)
+	}
+	p.PutErr(o, errors.New("discard"))
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := p.GetContext(ctx); err != context.Canceled {
+		t.Errorf("err=%v, want %v", err, context.Canceled)
+	}
+	if active := p.ActiveCount(); active != 0 {
+		t.Errorf("active=%d, want 0", active)
+	}
+
+	p.New = func() (interface{}, error) { return nil, nil }
+	if _, err := p.Get(); err != errNewFunc {
+		t.Errorf("err=%v, want %v", err, errNewFunc)
+	}
+}
+
+func TestWaitPoolContextCancel(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := &Pool{
+		New:       d.dial,
+		MaxIdle:   1,
+		MaxActive: 1,
+		Wait:      true,
+	}
+	defer p.Close()
+
+	o, _ := p.Get()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := p.GetContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("err=%v, want %v", err, context.DeadlineExceeded)
+	}
+	p.Put(o)
+	d.check("done", p, 1, 1)
+}
+
+func TestPoolPause(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+
+	o, _ := p.Get()
+	p.Pause()
+	if !p.IsPaused() {
+		t.Error("expected pool paused")
+	}
+	if _, err := p.Get(); err != ErrPoolPaused {
+		t.Errorf("err=%v, want %v", err, ErrPoolPaused)
+	}
+	p.Put(o)
+	d.check("paused", p, 1, 1)
+
+	p.Resume()
+	o, err := p.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Put(o)
+	d.check("resumed", p, 1, 1)
+	p.Close()
+}
+
+func TestWaitPoolPause(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := &Pool{
+		New:          d.dial,
+		MaxIdle:      1,
+		Wait:         true,
+		DropCallback: d.drop,
+	}
+	p.Pause()
+
+	errs := startGroutines(p)
+	if n := p.WaiterCount(); n != cap(errs) {
+		t.Errorf("waiters=%d, want %d", n, cap(errs))
+	}
+	waiters := p.WaitQueue()
+	for i := 1; i < len(waiters); i++ {
+		if waiters[i].WaitingSince.Before(waiters[i-1].WaitingSince) || waiters[i].ID == waiters[i-1].ID {
+			t.Errorf("unexpected wait queue %v", waiters)
+		}
+	}
+	p.Resume()
+
+	timeout := time.After(2 * time.Second)
+	for i := 0; i < cap(errs); i++ {
+		select {
+		case err := <-errs:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-timeout:
+			t.Fatalf("timeout waiting for blocked goroutine %d", i)
+		}
+	}
+
+	p.Pause()
+	errs = startGroutines(p)
+	p.Close()
+	for i := 0; i < cap(errs); i++ {
+		select {
+		case err := <-errs:
+			if err != ErrPoolClosed {
+				t.Fatalf("err=%v, want %v", err, ErrPoolClosed)
+			}
+		case <-timeout:
+			t.Fatalf("timeout waiting for blocked goroutine %d", i)
+		}
+	}
+}
+
+func TestPoolGetNoAlloc(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	defer p.Close()
+	o, _ := p.Get()
+	p.Put(o)
+
+	n := testing.AllocsPerRun(100, func() {
+		o, _ := p.Get()
+		p.Put(o) // 复用Get()时移除的链表元素
+	})
+	if n != 0 {
+		t.Errorf("Get() and Put() with an idle object allocated %v times", n)
+	}
+}
+
 func BenchmarkPoolGet(b *testing.B) {
 	b.StopTimer()
 	p := &Pool{
@@ -289,6 +967,7 @@ func BenchmarkPoolGet(b *testing.B) {
 	}
 	p.Put(o)
 
+	b.ReportAllocs() // Get()和Put()都不分配内存
 	b.StartTimer()
 
 	for i := 0; i < b.N; i++ {
@@ -300,3 +979,203 @@ func BenchmarkPoolGet(b *testing.B) {
 		}
 	}
 }
+
+// BenchmarkIdleListSmallMaxIdle 比较MaxIdle较小时借出和放回一个空闲对象的开销，
+// container_list是改用idleList之前的实现，每次放回都分配list.Element并装箱ConnectionInfo
+func BenchmarkIdleListSmallMaxIdle(b *testing.B) {
+	for _, maxIdle := range []int{2, 8} {
+		b.Run(fmt.Sprintf("container_list/%d", maxIdle), func(b *testing.B) {
+			var l list.List
+			for i := 0; i < maxIdle; i++ {
+				l.PushFront(ConnectionInfo{Obj: i})
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				io := l.Remove(l.Front()).(ConnectionInfo)
+				l.PushFront(io)
+			}
+		})
+		b.Run(fmt.Sprintf("idleList/%d", maxIdle), func(b *testing.B) {
+			var l idleList
+			for i := 0; i < maxIdle; i++ {
+				l.PushFront(ConnectionInfo{Obj: i})
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				io := l.Remove(l.Front())
+				l.PushFront(io)
+			}
+		})
+	}
+}
+
+// 锁竞争可以通过 go test -bench GetPutParallel -mutexprofile mutex.out 查看
+func BenchmarkPoolGetPutParallel(b *testing.B) {
+	newConn := func() (interface{}, error) {
+		return &conn{}, nil
+	}
+	benchmarks := []struct {
+		name      string
+		maxIdle   int
+		maxActive int
+		wait      bool
+		warmup    int
+	}{
+		{"LIFO_idle_only", 1024, 0, false, 1024},
+		{"MaxActive_limited_wait", 2, 2, true, 2},
+		{"all_misses", 0, 0, false, 0}, // 对象放回时都会被丢弃，每次Get()都创建新对象
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			p := &Pool{New: newConn, MaxIdle: bm.maxIdle, MaxActive: bm.maxActive, Wait: bm.wait}
+			defer p.Close()
+			if err := p.Warmup(bm.warmup); err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					o, err := p.Get()
+					if err != nil {
+						b.Error(err)
+						return
+					}
+					p.Put(o)
+				}
+			})
+		})
+	}
+}
+
+func TestPoolPutWithTTL(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+	p.IdleTimeout = time.Minute
+
+	now := time.Now()
+	nowFunc = func() time.Time {
+		return now
+	}
+	defer func() {
+		nowFunc = time.Now
+	}()
+
+	o1, _ := p.Get()
+	o2, _ := p.Get()
+	p.PutWithTTL(o1, time.Second)
+	p.Put(o2)
+	d.check("1", p, 2, 2)
+
+	now = now.Add(time.Second)
+	o, _ := p.Get()
+	if o != o2 {
+		t.Errorf("got %p, want %p", o, o2)
+	}
+	d.check("2", p, 2, 1)
+	p.Put(o)
+	p.Close()
+}
+
+func TestIdleListExpiring(t *testing.T) {
+	var l idleList
+	now := time.Now()
+	l.PushFront(ConnectionInfo{Obj: 1, IdleSince: now})
+	e2 := l.PushFront(ConnectionInfo{Obj: 2, IdleSince: now.Add(time.Second), expires: now.Add(time.Hour)})
+	e3 := l.PushFront(ConnectionInfo{Obj: 3, IdleSince: now.Add(2 * time.Second), expires: now.Add(time.Minute)})
+	if e := l.Expiring(); e != e3 { // 较新的对象可以先过期
+		t.Errorf("Expiring()=%v, want 3", e.Value.Obj)
+	}
+	if e := l.Oldest(); e.Value.Obj != 1 {
+		t.Errorf("Oldest()=%v, want 1", e.Value.Obj)
+	}
+	l.Remove(e3)
+	if e := l.Expiring(); e != e2 {
+		t.Errorf("Expiring()=%v, want 2", e.Value.Obj)
+	}
+	l.Remove(l.Oldest())
+	if e := l.Oldest(); e != e2 {
+		t.Errorf("Oldest()=%v, want 2", e.Value.Obj)
+	}
+	l.Remove(e2)
+	if e := l.Expiring(); e != nil {
+		t.Errorf("Expiring()=%v, want nil", e.Value.Obj)
+	}
+}
+
+func TestPoolTimeoutJitter(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+	p.IdleTimeout = time.Second
+	p.IdleTimeoutJitter = time.Second
+
+	now := time.Now()
+	nowFunc = func() time.Time {
+		return now
+	}
+	randInt63n = func(n int64) int64 {
+		return n - 1
+	}
+	defer func() {
+		nowFunc = time.Now
+		randInt63n = rand.Int63n
+	}()
+
+	o, _ := p.Get()
+	p.Put(o)
+
+	now = now.Add(time.Second)
+	o, _ = p.Get()
+	p.Put(o)
+	d.check("before jitter", p, 1, 1)
+
+	now = now.Add(2 * time.Second)
+	o, _ = p.Get()
+	p.Put(o)
+	d.check("after jitter", p, 2, 1)
+	p.Close()
+}
+
+func TestPoolTimeoutJitterOnce(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.IdleTimeout = time.Second
+	p.IdleTimeoutJitter = time.Second
+	defer p.Close()
+
+	draws := 0
+	randInt63n = func(n int64) int64 {
+		draws++
+		return n / 2
+	}
+	defer func() { randInt63n = rand.Int63n }()
+
+	for i := 0; i < 3; i++ {
+		o, _ := p.Get()
+		p.Put(o)
+	}
+	if draws != 1 {
+		t.Errorf("jitter drawn %d times, want 1", draws)
+	}
+	if info := p.IdleConnections()[0]; info.expires.Sub(info.IdleSince) != 1500*time.Millisecond {
+		t.Errorf("timeout=%v, want 1.5s", info.expires.Sub(info.IdleSince))
+	}
+}
+
+func TestPoolJanitor(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+	p.IdleTimeout = 20 * time.Millisecond
+	p.JanitorInterval = 10 * time.Millisecond
+
+	o, _ := p.Get()
+	p.Put(o)
+	waitActive(t, p, 0)
+	p.Close()
+	d.check("after close", p, 1, 0)
+}
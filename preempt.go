@@ -0,0 +1,39 @@
+package pool
+
+// preempt 在有goroutine等待时，对借出超过PreemptTimeout的对象调用PreemptCallback，
+// 返回true的对象不再计入active，返回false的对象再过PreemptTimeout才会被再次询问
+func (p *Pool) preempt() {
+	p.mu.Lock()
+	callback := p.PreemptCallback
+	if !p.TrackActive || callback == nil || len(p.waiters) == 0 {
+		p.mu.Unlock()
+		return
+	}
+	now := nowFunc()
+	var objs []interface{}
+	for obj, io := range p.borrowed {
+		since := io.borrowedAt
+		if io.preemptAfter.After(since) {
+			since = io.preemptAfter
+		}
+		if now.Sub(since) >= p.PreemptTimeout {
+			io.preemptAfter = now
+			p.borrowed[obj] = io
+			objs = append(objs, obj)
+		}
+	}
+	p.mu.Unlock()
+
+	for _, obj := range objs {
+		if !callback(obj) {
+			continue
+		}
+		p.mu.Lock()
+		if _, ok := p.borrowed[obj]; ok {
+			if io, ok := p.untrack(obj); ok {
+				p.retire(io)
+			}
+		}
+		p.mu.Unlock()
+	}
+}
@@ -0,0 +1,64 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPoolPreempt(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.MaxActive = 1
+	p.Wait = true
+	p.TrackActive = true
+	p.PreemptTimeout = time.Minute
+	giveUp := false
+	asked := 0
+	p.PreemptCallback = func(interface{}) bool {
+		asked++
+		return giveUp
+	}
+	defer p.Close()
+
+	now := time.Now()
+	nowFunc = func() time.Time {
+		return now
+	}
+	defer func() {
+		nowFunc = time.Now
+	}()
+
+	o1, _ := p.Get()
+	got := make(chan interface{})
+	go func() {
+		o, _ := p.Get()
+		got <- o
+	}()
+	for p.WaiterCount() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	p.preempt() // 还没有超过PreemptTimeout
+	now = now.Add(time.Minute)
+	p.preempt()
+	p.preempt() // 返回false之后要再等PreemptTimeout
+	if asked != 1 {
+		t.Errorf("PreemptCallback called %d times, want 1", asked)
+	}
+
+	giveUp = true
+	now = now.Add(time.Minute)
+	p.preempt()
+	o2 := <-got
+	if asked != 2 || o2 == o1 {
+		t.Errorf("asked=%d, o1=%v, o2=%v", asked, o1, o2)
+	}
+	p.Put(o1) // 已经被抢占，直接丢弃
+	p.Put(o2)
+	if n := p.IdleCount(); n != 1 {
+		t.Errorf("IdleCount()=%d, want 1", n)
+	}
+	if n := p.ActiveCount(); n != 1 {
+		t.Errorf("ActiveCount()=%d, want 1", n)
+	}
+}
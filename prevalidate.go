@@ -0,0 +1,113 @@
+package pool
+
+import "time"
+
+// prevalidating 返回这次是否需要同时检查多个空闲对象，不算已经分配给其他等待者的，调用时需持有锁
+func (p *Pool) prevalidating(fair bool, opts getOptions) bool {
+	avail := p.idle.Len()
+	if !fair {
+		avail -= p.assigned
+	}
+	return p.PrevalidationConcurrency > 1 && p.TestOnBorrow != nil && p.TestOnBorrowWithCount == nil &&
+		opts.prefer == nil && avail > 1
+}
+
+// prevalidate 与Get()相同地依次选出最多PrevalidationConcurrency个空闲对象（见nextIdle），同时对它们调用TestOnBorrow，
+// 返回最先通过检查的对象，其他对象在后台等检查结束后处理：通过的放回空闲列表，失败的丢弃。
+// 调用时需持有锁，没有通过检查的对象时返回false，返回时仍然持有锁
+func (p *Pool) prevalidate(gid int64, fair bool, opts getOptions, waited time.Duration) (interface{}, bool) {
+	max := p.PrevalidationConcurrency
+	if max > p.idle.Len() {
+		max = p.idle.Len()
+	}
+	ios := make([]ConnectionInfo, 0, max)
+	for len(ios) < max {
+		io, ok := p.nextIdle(fair && len(ios) == 0, opts)
+		if !ok {
+			break
+		}
+		ios = append(ios, io)
+	}
+	n := len(ios)
+	if n == 0 {
+		return nil, false
+	}
+	test, gen := p.TestOnBorrow, p.generation
+	multiplex := p.maxUses() > 1
+	events, observer := p.requestSink(opts.reqID), p.observer
+	p.mu.Unlock()
+
+	type result struct {
+		io  ConnectionInfo
+		err error
+	}
+	results := make(chan result, n)
+	for _, io := range ios {
+		go func(io ConnectionInfo) {
+			results <- result{io, test(io.Obj)}
+		}(io)
+	}
+	for i := 0; i < n; i++ {
+		r := <-results
+		if r.err != nil {
+			p.discardTested(r.io, r.err)
+			continue
+		}
+		go func(remaining int) {
+			for ; remaining > 0; remaining-- {
+				if r := <-results; r.err != nil {
+					p.discardTested(r.io, r.err)
+				} else {
+					p.restoreTested(r.io, gen)
+				}
+			}
+		}(n - i - 1)
+
+		io := r.io
+		p.mu.Lock()
+		io.Uses++
+		io.owner, io.label, io.reqID = gid, opts.label, opts.reqID
+		io.gen = p.generation // 检查期间调用了Reset时对象仍然计入active
+		p.track(io)
+		p.mu.Unlock()
+		if multiplex {
+			p.share(io.Obj)
+		}
+		p.stats.hits.Add(1)
+		publish(events, EventBorrowed, io.Obj, nil)
+		if observer != nil {
+			observer.OnGet(io.Obj, true, waited)
+		}
+		return io.Obj, true
+	}
+	p.mu.Lock()
+	return nil, false
+}
+
+// discardTested 丢弃没有通过检查的对象
+func (p *Pool) discardTested(io ConnectionInfo, err error) {
+	p.mu.Lock()
+	p.healthCheckFailed(io.Obj, "TestOnBorrow", err)
+	p.retire(io)
+	p.dropObjs(io.Obj)
+}
+
+// restoreTested 把通过检查但没有被借出的对象放回空闲列表（空闲超过MaxIdleTime的放回limbo），
+// pool已关闭或调用过Reset时丢弃
+func (p *Pool) restoreTested(io ConnectionInfo, gen uint64) {
+	p.mu.Lock()
+	if p.closed || gen != p.generation || p.idle.Len() >= p.maxIdle() {
+		p.retire(io)
+		p.dropObjs(io.Obj)
+		return
+	}
+	if io.limbo {
+		io.limbo = false
+		p.limbo.PushFront(io)
+		p.mu.Unlock()
+		return
+	}
+	p.idle.PushFront(io)
+	p.signal()
+	p.mu.Unlock()
+}
@@ -0,0 +1,111 @@
+package pool
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPoolPrevalidation(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 4)
+	dropped := make(chan interface{}, 4)
+	p.DropCallback = func(o interface{}) { dropped <- o }
+	p.PrevalidationConcurrency = 3
+	defer p.Close()
+
+	var objs []interface{}
+	for i := 0; i < 4; i++ {
+		o, _ := p.Get()
+		objs = append(objs, o)
+	}
+	for _, o := range objs {
+		p.Put(o)
+	}
+
+	unblock := make(chan struct{})
+	p.mu.Lock()
+	p.TestOnBorrow = func(o interface{}) error {
+		switch o.(*conn).id {
+		case 4: // 最新的对象检查得很慢
+			<-unblock
+		case 3:
+			return errors.New("broken")
+		}
+		return nil
+	}
+	p.mu.Unlock()
+
+	o, err := p.Get()
+	if err != nil || o != objs[1] {
+		t.Fatalf("Get()=%v, %v, want %v", o, err, objs[1])
+	}
+	if o := <-dropped; o != objs[2] {
+		t.Errorf("dropped %v, want %v", o, objs[2])
+	}
+	close(unblock)
+	deadline := time.Now().Add(2 * time.Second)
+	for p.IdleCount() != 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := p.IdleCount(); n != 2 {
+		t.Errorf("IdleCount()=%d, want 2", n)
+	}
+	if n := p.ActiveCount(); n != 3 {
+		t.Errorf("ActiveCount()=%d, want 3", n)
+	}
+	p.Put(o)
+}
+
+func TestPoolPrevalidationSelection(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 4)
+	p.DropCallback = d.drop
+	p.PrevalidationConcurrency = 2
+	p.ConnectionVersion = 1
+	p.TestOnBorrow = func(interface{}) error { return nil }
+	secondary := make(map[interface{}]bool)
+	p.Tier = func(obj interface{}) int {
+		if secondary[obj] {
+			return 1
+		}
+		return 0
+	}
+	defer p.Close()
+
+	var objs []interface{}
+	for i := 0; i < 3; i++ {
+		o, _ := p.Get()
+		objs = append(objs, o)
+	}
+	var err error
+	secondary[objs[2]] = true
+	for _, o := range objs {
+		p.Put(o)
+	}
+	o, _ := p.Get()
+	if o == objs[2] { // 和Get()一样优先检查等级小的
+		t.Errorf("Get()=%v, want a primary object", o)
+	}
+	p.Put(o)
+	deadline := time.Now().Add(2 * time.Second)
+	for p.IdleCount() != 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := p.IdleCount(); n != 3 {
+		t.Fatalf("IdleCount()=%d, want 3", n)
+	}
+
+	p.SetVersion(2)
+	o, err = p.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, old := range objs {
+		if o == old {
+			t.Errorf("Get()=%v, an object of an old version", o)
+		}
+	}
+	p.Put(o)
+	d.check("after version change", p, 4, 1)
+}
@@ -0,0 +1,59 @@
+package pool
+
+import (
+	"context"
+	"errors"
+)
+
+// maxProbeBackoff 是探测连续失败后最多跳过的次数
+const maxProbeBackoff = 32
+
+// probeExhausted 在达到MaxActive且没有空闲对象时尝试创建一个对象放入空闲列表。
+// 探测创建的对象会计入active，所以active最多比MaxActive多一个，active超出MaxActive时放回的对象会被丢弃以归还这个位置。
+// 连续失败时跳过的次数会翻倍
+func (p *Pool) probeExhausted() {
+	p.mu.Lock()
+	max := p.maxActive()
+	if p.closed || p.Wait || max <= 0 || p.active != max || p.idle.Len() > 0 {
+		p.mu.Unlock()
+		return
+	}
+	if p.probeSkip > 0 {
+		p.probeSkip--
+		p.mu.Unlock()
+		return
+	}
+	if p.takeDialToken() > 0 {
+		p.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), p.ExhaustionProbeInterval)
+	defer cancel()
+	err := p.addIdle(ctx, true, nil)
+	if errors.Is(err, ErrGroupFull) || errors.Is(err, ErrPoolClosed) {
+		return
+	}
+	p.mu.Lock()
+	if err != nil {
+		if p.probeBackoff < maxProbeBackoff {
+			p.probeBackoff = p.probeBackoff*2 + 1
+		}
+		p.probeSkip = p.probeBackoff
+	} else {
+		p.probeBackoff = 0
+	}
+	p.mu.Unlock()
+}
+
+// returnProbeSlot 在active因为探测超出MaxActive时归还探测占用的位置并返回true，这时放回的对象需要丢弃，调用时需持有锁
+func (p *Pool) returnProbeSlot() bool {
+	if p.probed == 0 {
+		return false
+	}
+	if max := p.maxActive(); max <= 0 || p.active <= max { // 已经有对象被丢弃了
+		p.probed = 0
+		return false
+	}
+	p.probed--
+	return true
+}
@@ -0,0 +1,63 @@
+package pool
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPoolExhaustionProbe(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+	p.MaxActive = 1
+
+	o1, _ := p.Get()
+	p.ExhaustionProbeInterval = time.Second // 后台goroutine已经在第一次Get()时决定，这里直接调用probeExhausted
+	if _, err := p.Get(); !errors.Is(err, ErrPoolExhausted) {
+		t.Fatalf("Get()=%v, want ErrPoolExhausted", err)
+	}
+	p.probeExhausted()
+	d.check("probe", p, 2, 2)
+	o2, err := p.Get()
+	if err != nil || o2 == o1 {
+		t.Fatalf("Get()=%v, %v after probe", o2, err)
+	}
+	p.probeExhausted() // 已经多了一个对象，不再探测
+	d.check("second probe", p, 2, 2)
+
+	p.Put(o1) // 超出MaxActive，丢弃以归还探测占用的位置
+	d.check("put first", p, 2, 1)
+	p.Put(o2)
+	if n := p.IdleCount(); n != 1 {
+		t.Errorf("IdleCount()=%d, want 1", n)
+	}
+	p.Close()
+	d.check("after close", p, 2, 0)
+}
+
+func TestPoolExhaustionProbeBackoff(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+	p.MaxActive = 1
+	defer p.Close()
+
+	o, _ := p.Get()
+	defer p.Put(o)
+	p.ExhaustionProbeInterval = time.Second
+	dials := 0
+	p.New = func() (interface{}, error) {
+		dials++
+		return nil, errors.New("refused")
+	}
+	for i := 0; i < 7; i++ { // 失败后分别跳过1次和3次
+		p.probeExhausted()
+	}
+	if dials != 3 {
+		t.Errorf("dials=%d, want 3", dials)
+	}
+	if n := p.ActiveCount(); n != 1 {
+		t.Errorf("ActiveCount()=%d, want 1", n)
+	}
+}
@@ -0,0 +1,9 @@
+package pool
+
+import "context"
+
+// RateLimiter 限制Get()的速率，*rate.Limiter（golang.org/x/time/rate）实现了这个接口
+type RateLimiter interface {
+	// Wait 阻塞到允许获取下一个对象，ctx被取消时返回错误
+	Wait(ctx context.Context) error
+}
@@ -0,0 +1,55 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// countLimiter 允许前n次Wait，之后返回errLimited
+type countLimiter struct {
+	n     int
+	calls int
+}
+
+var errLimited = errors.New("rate limited")
+
+func (l *countLimiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	l.calls++
+	if l.calls > l.n {
+		return errLimited
+	}
+	return nil
+}
+
+func TestPoolGetRateLimiter(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	l := &countLimiter{n: 2}
+	p.GetRateLimiter = l
+	defer p.Close()
+
+	o, err := p.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Put(o)
+	if _, ok := p.GetIfAvailable(); !ok { // 不受限制
+		t.Error("GetIfAvailable() failed")
+	}
+	if _, err := p.Get(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Get(); err != errLimited {
+		t.Errorf("Get()=%v, want %v", err, errLimited)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := p.GetContext(ctx); err != context.Canceled {
+		t.Errorf("GetContext()=%v, want context.Canceled", err)
+	}
+	d.check("rate limited", p, 2, 2)
+}
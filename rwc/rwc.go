@@ -0,0 +1,74 @@
+// Package rwc 提供保存io.ReadWriteCloser的pool，取出的对象不需要再做类型断言
+package rwc
+
+import (
+	"io"
+
+	"github.com/chen-zyc/pool"
+)
+
+// RWCPool 包装了pool.Pool，丢弃对象时会自动调用它的Close()
+type RWCPool struct {
+	*pool.Pool
+}
+
+func NewRWCPool(New func() (io.ReadWriteCloser, error), maxIdle int) *RWCPool {
+	p := pool.NewPool(func() (interface{}, error) {
+		return New()
+	}, maxIdle)
+	p.DropCallback = func(obj interface{}) {
+		obj.(io.ReadWriteCloser).Close()
+	}
+	return &RWCPool{Pool: p}
+}
+
+// Get 与pool.Pool.Get()相同，但直接返回io.ReadWriteCloser，用完后需要调用Put()放回
+func (p *RWCPool) Get() (io.ReadWriteCloser, error) {
+	obj, err := p.Pool.Get()
+	if err != nil {
+		return nil, err
+	}
+	return obj.(io.ReadWriteCloser), nil
+}
+
+// Acquire 获取一个连接，调用RWCConn.Close()放回pool，调用Discard()丢弃
+func (p *RWCPool) Acquire() (*RWCConn, error) {
+	c, err := p.Get()
+	if err != nil {
+		return nil, err
+	}
+	return &RWCConn{p: p, conn: c}, nil
+}
+
+// RWCConn 是从RWCPool中取出的连接，Close()或Discard()之后不能再使用
+type RWCConn struct {
+	p    *RWCPool
+	conn io.ReadWriteCloser
+}
+
+func (c *RWCConn) Read(b []byte) (int, error) {
+	return c.conn.Read(b)
+}
+
+func (c *RWCConn) Write(b []byte) (int, error) {
+	return c.conn.Write(b)
+}
+
+// Close 把连接放回pool，并不会关闭底层连接，多次调用只有第一次有效
+func (c *RWCConn) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	c.p.Put(c.conn)
+	c.conn = nil
+	return nil
+}
+
+// Discard 丢弃连接，底层连接会被关闭，用于连接出错的情况
+func (c *RWCConn) Discard() {
+	if c.conn == nil {
+		return
+	}
+	c.p.PutErr(c.conn, io.ErrClosedPipe)
+	c.conn = nil
+}
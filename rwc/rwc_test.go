@@ -0,0 +1,58 @@
+package rwc
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+type fakeConn struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (c *fakeConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestRWCPool(t *testing.T) {
+	var conns []*fakeConn
+	p := NewRWCPool(func() (io.ReadWriteCloser, error) {
+		c := &fakeConn{}
+		conns = append(conns, c)
+		return c, nil
+	}, 1)
+
+	c, err := p.Acquire()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 5)
+	if n, _ := c.Read(buf); string(buf[:n]) != "hello" {
+		t.Errorf("read %q, want hello", buf[:n])
+	}
+	c.Close()
+	c.Close()
+	if conns[0].closed {
+		t.Error("conn closed after Close()")
+	}
+	if n := p.IdleCount(); n != 1 {
+		t.Errorf("IdleCount()=%d, want 1", n)
+	}
+
+	c, _ = p.Acquire()
+	if len(conns) != 1 {
+		t.Errorf("dialed %d conns, want 1", len(conns))
+	}
+	c.Discard()
+	if !conns[0].closed {
+		t.Error("conn not closed after Discard()")
+	}
+	if n := p.ActiveCount(); n != 0 {
+		t.Errorf("ActiveCount()=%d, want 0", n)
+	}
+}
@@ -0,0 +1,54 @@
+package pool
+
+import (
+	"context"
+	"time"
+)
+
+// ScopedPool 包装一个Pool，每次Get()最多等待Timeout，用于在不修改pool和调用代码时给不同的调用者设置不同的超时，
+// 如交互请求和批处理任务使用同一个pool的两个ScopedPool
+type ScopedPool struct {
+	Pool    *Pool
+	Timeout time.Duration // 为0时不限制
+}
+
+// WithTimeout 返回一个每次Get()最多等待d的ScopedPool，超时返回context.DeadlineExceeded
+func (p *Pool) WithTimeout(d time.Duration) *ScopedPool {
+	return &ScopedPool{Pool: p, Timeout: d}
+}
+
+func (s *ScopedPool) Get() (interface{}, error) {
+	return s.GetContext(context.Background())
+}
+
+// GetContext 在ctx和Timeout中较早的期限内获取对象
+func (s *ScopedPool) GetContext(ctx context.Context) (interface{}, error) {
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+	return s.Pool.GetContext(ctx)
+}
+
+func (s *ScopedPool) Put(obj interface{}) {
+	s.Pool.Put(obj)
+}
+
+func (s *ScopedPool) PutErr(obj interface{}, err error) {
+	s.Pool.PutErr(obj, err)
+}
+
+// Close 关闭底层的pool，会影响所有包装了它的ScopedPool
+func (s *ScopedPool) Close() {
+	s.Pool.Close()
+}
+
+// OnceClose 与Close相同，但底层的pool只会被关闭一次
+func (s *ScopedPool) OnceClose() {
+	s.Pool.OnceClose()
+}
+
+func (s *ScopedPool) ActiveCount() int {
+	return s.Pool.ActiveCount()
+}
@@ -0,0 +1,36 @@
+package pool
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestScopedPool(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 1)
+	p.MaxActive = 1
+	p.Wait = true
+	defer p.Close()
+
+	short, long := p.WithTimeout(10*time.Millisecond), p.WithTimeout(time.Second)
+	o, _ := short.Get()
+	if _, err := short.Get(); err != context.DeadlineExceeded {
+		t.Errorf("short.Get()=%v, want DeadlineExceeded", err)
+	}
+
+	got := make(chan error)
+	go func() {
+		o, err := long.Get()
+		if err == nil {
+			long.Put(o)
+		}
+		got <- err
+	}()
+	waitWaiters(t, p, 1)
+	short.Put(o)
+	if err := <-got; err != nil {
+		t.Errorf("long.Get()=%v", err)
+	}
+	d.check("scoped", p, 1, 1)
+}
@@ -0,0 +1,44 @@
+package pool
+
+// scoreIdle 对当前的空闲对象调用HealthScore并记录分数，丢弃分数低于EvictScoreThreshold的对象。
+// 打分时不持有锁，之后通过元素的序号找回仍在空闲列表中的对象，不需要比较对象本身
+func (p *Pool) scoreIdle() {
+	p.mu.Lock()
+	score, threshold := p.HealthScore, p.EvictScoreThreshold
+	if score == nil || p.closed {
+		p.mu.Unlock()
+		return
+	}
+	type scored struct {
+		e     *idleElem
+		seq   uint64
+		obj   interface{}
+		score float64
+	}
+	items := make([]scored, 0, p.idle.Len())
+	for e := p.idle.Front(); e != nil; e = e.Next() {
+		items = append(items, scored{e: e, seq: e.seq, obj: e.Value.Obj})
+	}
+	p.mu.Unlock()
+
+	for i := range items {
+		items[i].score = score(items[i].obj)
+	}
+
+	p.mu.Lock()
+	var evicted []interface{}
+	for _, it := range items {
+		e := it.e
+		if e.list != &p.idle || e.seq != it.seq { // 打分期间被借出了
+			continue
+		}
+		if threshold > 0 && it.score < threshold {
+			p.retire(p.idle.Remove(e))
+			p.evict(it.obj, "low score")
+			evicted = append(evicted, it.obj)
+			continue
+		}
+		p.idle.SetScore(e, it.score)
+	}
+	p.dropObjs(evicted...)
+}
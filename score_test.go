@@ -0,0 +1,58 @@
+package pool
+
+import "testing"
+
+func TestPoolHealthScore(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 3)
+	p.DropCallback = d.drop
+	p.HealthScore = func(obj interface{}) float64 { return float64(obj.(*conn).id) }
+	p.EvictScoreThreshold = 1.5
+	defer p.Close()
+
+	var objs []interface{}
+	for i := 0; i < 3; i++ {
+		o, _ := p.Get()
+		objs = append(objs, o)
+	}
+	for i := len(objs) - 1; i >= 0; i-- { // id为1的在最前面
+		p.Put(objs[i])
+	}
+
+	p.scoreIdle()
+	d.check("after scoring", p, 3, 2)
+	o, _ := p.Get()
+	if o != objs[2] {
+		t.Errorf("Get()=%v, want the highest scored %v", o, objs[2])
+	}
+	p.Put(o)
+	if o2, _ := p.Get(); o2 != o { // 分数在借出之后仍然保留
+		t.Errorf("Get()=%v, want %v", o2, o)
+	}
+	p.Put(o)
+}
+
+func TestIdleListBest(t *testing.T) {
+	var l idleList
+	e1 := l.PushFront(ConnectionInfo{Obj: 1})
+	e2 := l.PushFront(ConnectionInfo{Obj: 2})
+	e3 := l.PushFront(ConnectionInfo{Obj: 3, tier: 1})
+	if e := l.Best(); e != e2 { // 分数相同时返回较新的
+		t.Errorf("Best()=%v, want 2", e.Value.Obj)
+	}
+	l.SetScore(e1, 5)
+	l.SetScore(e3, 10)
+	if e := l.Best(); e != e1 { // 等级优先
+		t.Errorf("Best()=%v, want 1", e.Value.Obj)
+	}
+	l.Remove(e1)
+	e4 := l.PushBack(ConnectionInfo{Obj: 4, score: 1})
+	if e := l.Best(); e != e4 {
+		t.Errorf("Best()=%v, want 4", e.Value.Obj)
+	}
+	l.Remove(e2)
+	l.Remove(e4)
+	if e := l.Best(); e != e3 {
+		t.Errorf("Best()=%v, want 3", e.Value.Obj)
+	}
+}
@@ -0,0 +1,42 @@
+package pool
+
+// ShrinkPolicy 决定janitor每次运行时丢弃多少个空闲对象，用于在负载降低时释放多余的连接。
+// idle是空闲对象的数量，active是所有对象（包括空闲的）的数量
+type ShrinkPolicy interface {
+	ShouldShrink(idle, active, maxIdle int) int
+}
+
+// NoShrink 不丢弃空闲对象，与不设置ShrinkPolicy相同
+type NoShrink struct{}
+
+func (NoShrink) ShouldShrink(idle, active, maxIdle int) int {
+	return 0
+}
+
+// GradualShrink 每次丢弃Rate比例的空闲对象，至少一个
+type GradualShrink struct {
+	Rate float64
+}
+
+func (s GradualShrink) ShouldShrink(idle, active, maxIdle int) int {
+	if idle == 0 || s.Rate <= 0 {
+		return 0
+	}
+	n := int(s.Rate * float64(idle))
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// AggressiveShrink 每次都把空闲对象减少到TargetIdle个
+type AggressiveShrink struct {
+	TargetIdle int
+}
+
+func (s AggressiveShrink) ShouldShrink(idle, active, maxIdle int) int {
+	if idle <= s.TargetIdle {
+		return 0
+	}
+	return idle - s.TargetIdle
+}
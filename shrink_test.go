@@ -0,0 +1,51 @@
+package pool
+
+import "testing"
+
+func TestShrinkPolicy(t *testing.T) {
+	tests := []struct {
+		policy ShrinkPolicy
+		idle   int
+		want   int
+	}{
+		{NoShrink{}, 10, 0},
+		{GradualShrink{Rate: 0.5}, 10, 5},
+		{GradualShrink{Rate: 0.1}, 3, 1},
+		{GradualShrink{Rate: 0.5}, 0, 0},
+		{AggressiveShrink{TargetIdle: 2}, 10, 8},
+		{AggressiveShrink{TargetIdle: 2}, 1, 0},
+	}
+	for _, tt := range tests {
+		if n := tt.policy.ShouldShrink(tt.idle, tt.idle, 10); n != tt.want {
+			t.Errorf("%#v.ShouldShrink(%d)=%d, want %d", tt.policy, tt.idle, n, tt.want)
+		}
+	}
+}
+
+func TestPoolShrinkPolicy(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 4)
+	p.DropCallback = d.drop
+	p.ShrinkPolicy = GradualShrink{Rate: 0.5}
+	defer p.Close()
+
+	var objs []interface{}
+	for i := 0; i < 4; i++ {
+		o, _ := p.Get()
+		objs = append(objs, o)
+	}
+	for _, o := range objs {
+		p.Put(o)
+	}
+
+	p.janitor()
+	d.check("first shrink", p, 4, 2)
+	if o, _ := p.Get(); o != objs[3] { // 丢弃的是最旧的
+		t.Errorf("Get()=%v, want %v", o, objs[3])
+	} else {
+		p.Put(o)
+	}
+	p.janitor()
+	p.janitor()
+	d.check("after shrink", p, 4, 0)
+}
@@ -0,0 +1,19 @@
+package pool
+
+// slowStart 在慢启动阶段增加slowStartActive，达到MaxActive后结束慢启动
+func (p *Pool) slowStart() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.slowStarting {
+		return
+	}
+	step := p.SlowStartStep
+	if step <= 0 {
+		step = 1
+	}
+	p.slowStartActive += step
+	if p.slowStartActive >= p.MaxActive {
+		p.slowStarting = false
+	}
+	p.broadcast()
+}
@@ -0,0 +1,48 @@
+package pool
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPoolSlowStart(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 4)
+	p.DropCallback = d.drop
+	p.MaxActive = 4
+	p.SlowStartInitial = 1
+	p.SlowStartStep = 2
+	p.SlowStartInterval = 20 * time.Millisecond
+	defer p.Close()
+
+	o1, _ := p.Get()
+	if _, err := p.Get(); !errors.Is(err, ErrPoolExhausted) {
+		t.Errorf("err=%v, want %v", err, ErrPoolExhausted)
+	}
+
+	// 等待者在慢启动增加容量后被唤醒
+	p.mu.Lock()
+	p.Wait = true
+	p.mu.Unlock()
+	var objs []interface{}
+	for i := 0; i < 3; i++ {
+		o, err := p.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		objs = append(objs, o)
+	}
+	if n := p.ActiveCount(); n != 4 {
+		t.Errorf("ActiveCount()=%d, want 4", n)
+	}
+	p.mu.Lock()
+	if p.slowStarting {
+		t.Error("slow start not finished")
+	}
+	p.mu.Unlock()
+	p.Put(o1)
+	for _, o := range objs {
+		p.Put(o)
+	}
+}
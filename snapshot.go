@@ -0,0 +1,89 @@
+package pool
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// PoolSnapshot 是某一时刻pool的状态，所有字段在同一次加锁中得到
+type PoolSnapshot struct {
+	Active    int
+	Idle      int
+	Waiting   int
+	MaxActive int // 当前生效的上限，考虑了MaxIdlePercent、慢启动、降级等
+	MaxIdle   int
+	Closed    bool
+	Stats     PoolStats
+
+	IdleConnectionAges []time.Duration // 每个空闲对象的空闲时间，最近放回的在前
+	Timestamp          time.Time
+}
+
+// Snapshot 返回pool当前状态的快照
+func (p *Pool) Snapshot() PoolSnapshot {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := nowFunc()
+	s := PoolSnapshot{
+		Active:    p.active,
+		Idle:      p.idle.Len(),
+		Waiting:   len(p.waiters),
+		MaxActive: p.maxActive(),
+		MaxIdle:   p.maxIdle(),
+		Closed:    p.closed,
+		Stats:     p.Stats(),
+		Timestamp: now,
+	}
+	s.IdleConnectionAges = make([]time.Duration, 0, s.Idle)
+	for e := p.idle.Front(); e != nil; e = e.Next() {
+		s.IdleConnectionAges = append(s.IdleConnectionAges, now.Sub(e.Value.IdleSince))
+	}
+	return s
+}
+
+// String 把快照格式化成YAML
+func (s PoolSnapshot) String() string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "timestamp: %s\n", s.Timestamp.Format(time.RFC3339Nano))
+	fmt.Fprintf(&b, "active: %d\nidle: %d\nwaiting: %d\n", s.Active, s.Idle, s.Waiting)
+	fmt.Fprintf(&b, "max_active: %d\nmax_idle: %d\nclosed: %t\n", s.MaxActive, s.MaxIdle, s.Closed)
+	fmt.Fprintf(&b, "stats:\n  hits: %d\n  misses: %d\n  errors: %d\n  evictions: %d\n  exhausted: %d\n  waits: %d\n",
+		s.Stats.Hits, s.Stats.Misses, s.Stats.Errors, s.Stats.Evictions, s.Stats.Exhausted, s.Stats.Waits)
+	if len(s.IdleConnectionAges) == 0 {
+		b.WriteString("idle_connection_ages: []\n")
+	} else {
+		b.WriteString("idle_connection_ages:\n")
+		for _, age := range s.IdleConnectionAges {
+			fmt.Fprintf(&b, "  - %s\n", age)
+		}
+	}
+	return b.String()
+}
+
+// PoolDiff 是两个快照之间的变化，都是b减去a
+type PoolDiff struct {
+	Elapsed time.Duration
+	Active  int
+	Idle    int
+	Waiting int
+	Stats   PoolStats
+}
+
+// Diff 返回从快照a到快照b的变化
+func Diff(a, b PoolSnapshot) PoolDiff {
+	return PoolDiff{
+		Elapsed: b.Timestamp.Sub(a.Timestamp),
+		Active:  b.Active - a.Active,
+		Idle:    b.Idle - a.Idle,
+		Waiting: b.Waiting - a.Waiting,
+		Stats: PoolStats{
+			Hits:      b.Stats.Hits - a.Stats.Hits,
+			Misses:    b.Stats.Misses - a.Stats.Misses,
+			Errors:    b.Stats.Errors - a.Stats.Errors,
+			Evictions: b.Stats.Evictions - a.Stats.Evictions,
+			Exhausted: b.Stats.Exhausted - a.Stats.Exhausted,
+			Waits:     b.Stats.Waits - a.Stats.Waits,
+		},
+	}
+}
@@ -0,0 +1,76 @@
+package pool
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPoolSnapshot(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.MaxActive = 3
+	defer p.Close()
+
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	nowFunc = func() time.Time {
+		return now
+	}
+	defer func() {
+		nowFunc = time.Now
+	}()
+
+	a := p.Snapshot()
+	o1, _ := p.Get()
+	o2, _ := p.Get()
+	p.Put(o1)
+	now = now.Add(time.Second)
+	b := p.Snapshot()
+	p.Put(o2)
+
+	if b.Active != 2 || b.Idle != 1 || b.MaxActive != 3 || b.MaxIdle != 2 || b.Closed {
+		t.Errorf("unexpected snapshot %+v", b)
+	}
+	if len(b.IdleConnectionAges) != 1 || b.IdleConnectionAges[0] != time.Second {
+		t.Errorf("IdleConnectionAges=%v, want [1s]", b.IdleConnectionAges)
+	}
+	want := `timestamp: 2020-01-02T03:04:06Z
+active: 2
+idle: 1
+waiting: 0
+max_active: 3
+max_idle: 2
+closed: false
+stats:
+  hits: 0
+  misses: 2
+  errors: 0
+  evictions: 0
+  exhausted: 0
+  waits: 0
+idle_connection_ages:
+  - 1s
+`
+	if s := b.String(); s != want {
+		t.Errorf("String()=\n%s\nwant\n%s", s, want)
+	}
+	if s := a.String(); !strings.Contains(s, "idle_connection_ages: []\n") {
+		t.Errorf("String()=\n%s\nwant empty idle_connection_ages", s)
+	}
+
+	diff := Diff(a, b)
+	if diff.Elapsed != time.Second || diff.Active != 2 || diff.Idle != 1 || diff.Stats.Misses != 2 {
+		t.Errorf("Diff()=%+v", diff)
+	}
+}
+
+func TestPoolSnapshotEffectiveLimits(t *testing.T) {
+	p := NewPool(nil, 2)
+	p.MaxActive = 10
+	p.MaxIdlePercent = 0.5
+	defer p.Close()
+
+	if s := p.Snapshot(); s.MaxActive != 10 || s.MaxIdle != 5 {
+		t.Errorf("MaxActive=%d MaxIdle=%d, want 10 5", s.MaxActive, s.MaxIdle)
+	}
+}
@@ -0,0 +1,247 @@
+package pool
+
+import (
+	"bytes"
+	"fmt"
+	"text/tabwriter"
+	"time"
+)
+
+// ConnectionState 是对象在pool中的状态
+type ConnectionState int
+
+const (
+	StateIdle     ConnectionState = iota // 在空闲列表中
+	StateBorrowed                        // 已被借出
+	StateClosing                         // 正在调用DropCallback
+)
+
+func (s ConnectionState) String() string {
+	switch s {
+	case StateIdle:
+		return "idle"
+	case StateBorrowed:
+		return "borrowed"
+	case StateClosing:
+		return "closing"
+	}
+	return "unknown"
+}
+
+// ConnectionEntry 描述pool中的一个对象
+type ConnectionEntry struct {
+	Obj        interface{}
+	State      ConnectionState
+	BorrowedAt time.Time // 最近一次被借出的时间
+	IdleSince  time.Time // 开始空闲的时间，只在State为StateIdle时有效
+	Uses       int       // 被借出的次数
+}
+
+// Connections 返回pool中所有对象的状态快照，需要开启TrackState
+func (p *Pool) Connections() []ConnectionEntry {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.TrackState {
+		return nil
+	}
+
+	entries := make([]ConnectionEntry, 0, p.idle.Len()+len(p.borrowed)+len(p.closing))
+	for e := p.idle.Front(); e != nil; e = e.Next() {
+		io := e.Value
+		entries = append(entries, ConnectionEntry{
+			Obj:        io.Obj,
+			State:      StateIdle,
+			BorrowedAt: io.borrowedAt,
+			IdleSince:  io.IdleSince,
+			Uses:       io.Uses,
+		})
+	}
+	for _, io := range p.borrowed {
+		entries = append(entries, ConnectionEntry{
+			Obj:        io.Obj,
+			State:      StateBorrowed,
+			BorrowedAt: io.borrowedAt,
+			Uses:       io.Uses,
+		})
+	}
+	for obj := range p.closing {
+		entries = append(entries, ConnectionEntry{
+			Obj:   obj,
+			State: StateClosing,
+		})
+	}
+	return entries
+}
+
+// DumpEntry 是Dump()返回的一个对象的信息
+type DumpEntry struct {
+	State     string // "idle"或"active"
+	IdleSince time.Time
+	Uses      int
+	CreatedAt time.Time
+}
+
+// Dump 返回所有空闲对象的信息，开启TrackActive时还包括借出的对象
+func (p *Pool) Dump() []DumpEntry {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entries := make([]DumpEntry, 0, p.idle.Len()+len(p.borrowed))
+	for e := p.idle.Front(); e != nil; e = e.Next() {
+		io := e.Value
+		entries = append(entries, DumpEntry{
+			State:     "idle",
+			IdleSince: io.IdleSince,
+			Uses:      io.Uses,
+			CreatedAt: io.CreatedAt,
+		})
+	}
+	if p.TrackActive {
+		for _, io := range p.borrowed {
+			entries = append(entries, DumpEntry{
+				State:     "active",
+				Uses:      io.Uses,
+				CreatedAt: io.CreatedAt,
+			})
+		}
+	}
+	return entries
+}
+
+// DumpString 把Dump()的结果格式化成表格
+func (p *Pool) DumpString() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STATE\tIDLE SINCE\tUSES\tCREATED AT")
+	for _, e := range p.Dump() {
+		idleSince := "-"
+		if !e.IdleSince.IsZero() {
+			idleSince = e.IdleSince.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", e.State, idleSince, e.Uses, e.CreatedAt.Format(time.RFC3339))
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// Range 对pool中的每个对象调用fn，state为"idle"或"active"，借出的对象只在开启TrackActive时包括。
+// fn返回false时停止。fn是在锁外对加锁时的快照调用的，可以有I/O，但对象可能已经被借出或放回，只适合只读的检查
+func (p *Pool) Range(fn func(obj interface{}, state string) bool) {
+	type entry struct {
+		obj   interface{}
+		state string
+	}
+	p.mu.Lock()
+	entries := make([]entry, 0, p.idle.Len()+len(p.borrowed))
+	for e := p.idle.Front(); e != nil; e = e.Next() {
+		entries = append(entries, entry{e.Value.Obj, "idle"})
+	}
+	if p.TrackActive {
+		for obj := range p.borrowed {
+			entries = append(entries, entry{obj, "active"})
+		}
+	}
+	p.mu.Unlock()
+
+	for _, e := range entries {
+		if !fn(e.obj, e.state) {
+			return
+		}
+	}
+}
+
+// IdleConnections 返回所有空闲对象的ConnectionInfo，最近放回的在前
+func (p *Pool) IdleConnections() []ConnectionInfo {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	infos := make([]ConnectionInfo, 0, p.idle.Len())
+	for e := p.idle.Front(); e != nil; e = e.Next() {
+		infos = append(infos, e.Value)
+	}
+	return infos
+}
+
+// Inspect 持有锁对每个空闲对象调用fn，最近放回的在前，对象不会被借出。
+// fn中不能调用Get、Put、Close等需要锁的方法，否则会死锁
+func (p *Pool) Inspect(fn func(obj interface{})) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for e := p.idle.Front(); e != nil; e = e.Next() {
+		fn(e.Value.Obj)
+	}
+}
+
+// ForEachIdle 持有锁从最新的开始对每个空闲对象调用fn，fn返回false的对象会被移除并丢弃（调用DropCallback），
+// 返回丢弃的数量。fn中不能调用Get、Put、Close等需要锁的方法，否则会死锁
+func (p *Pool) ForEachIdle(fn func(obj interface{}, info ConnectionInfo) bool) int {
+	p.mu.Lock()
+	var objs []interface{}
+	for e := p.idle.Front(); e != nil; {
+		next := e.Next()
+		if io := e.Value; !fn(io.Obj, io) {
+			p.idle.Remove(e)
+			p.retire(io)
+			p.evict(io.Obj, "filtered")
+			objs = append(objs, io.Obj)
+		}
+		e = next
+	}
+	p.dropObjs(objs...)
+	return len(objs)
+}
+
+// Filter 对每个空闲对象调用keep，移除并丢弃（调用DropCallback）keep返回false的对象，返回丢弃的数量。
+// 与ForEachIdle不同，keep在锁外调用，每移除一个对象才加一次锁，期间可以正常Get和Put；
+// 调用keep时已经被借出的对象不会被移除（即使之后又放回了）。不会比较对象，对象可以是slice等不能比较的类型
+func (p *Pool) Filter(keep func(obj interface{}) bool) int {
+	type filterRef struct {
+		idleRef
+		obj interface{}
+	}
+	p.mu.Lock()
+	refs := make([]filterRef, 0, p.idle.Len())
+	for e := p.idle.Front(); e != nil; e = e.Next() {
+		refs = append(refs, filterRef{idleRef{e, e.seq}, e.Value.Obj})
+	}
+	p.mu.Unlock()
+
+	n := 0
+	for _, r := range refs {
+		if keep(r.obj) {
+			continue
+		}
+		p.mu.Lock()
+		obj := r.obj
+		if !r.in(&p.idle) { // 已经被借出了，元素可能被其他对象复用
+			p.mu.Unlock()
+			continue
+		}
+		p.retire(p.idle.Remove(r.e))
+		p.evict(obj, "filtered")
+		p.dropObjs(obj)
+		n++
+	}
+	return n
+}
+
+// Map 持有锁用fn(obj)的返回值替换每个空闲对象（包括空闲超过MaxIdleTime的），如给对象加上一层包装。
+// fn中不能有I/O等耗时操作，也不能调用Get、Put、Close等需要锁的方法
+func (p *Pool) Map(fn func(interface{}) interface{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, l := range []*idleList{&p.idle, &p.limbo} {
+		for e := l.Front(); e != nil; e = e.Next() {
+			io := e.Value
+			io.Obj = fn(io.Obj)
+			e.Value = io
+		}
+	}
+}
+
+// WithLock 持有pool的锁调用fn，用于在检查pool状态的同时原子地修改调用者自己的状态。
+// fn中不能调用pool的任何方法，否则会死锁
+func (p *Pool) WithLock(fn func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fn()
+}
@@ -0,0 +1,293 @@
+package pool
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPoolConnections(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.TrackState = true
+
+	closing := make(chan []ConnectionEntry, 1)
+	p.DropCallback = func(o interface{}) {
+		closing <- p.Connections()
+		d.drop(o)
+	}
+
+	o1, _ := p.Get()
+	o2, _ := p.Get()
+	p.Put(o1)
+
+	states := map[interface{}]ConnectionState{}
+	for _, e := range p.Connections() {
+		states[e.Obj] = e.State
+		if e.Uses != 1 {
+			t.Errorf("%v uses=%d, want 1", e.State, e.Uses)
+		}
+	}
+	if len(states) != 2 || states[o1] != StateIdle || states[o2] != StateBorrowed {
+		t.Errorf("unexpected states %v", states)
+	}
+
+	p.PutErr(o2, errors.New("broken"))
+	entries := <-closing
+	found := false
+	for _, e := range entries {
+		if e.Obj == o2 {
+			found = e.State == StateClosing
+		}
+	}
+	if !found {
+		t.Errorf("expected %p closing in %v", o2, entries)
+	}
+	if n := len(p.Connections()); n != 1 {
+		t.Errorf("connections=%d, want 1", n)
+	}
+
+	p.Close()
+	<-closing
+	d.check("after close", p, 2, 0)
+}
+
+func TestPoolDump(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.TrackActive = true
+	defer p.Close()
+
+	o1, _ := p.Get()
+	o2, _ := p.Get()
+	p.Put(o1)
+
+	states := map[string]int{}
+	for _, e := range p.Dump() {
+		states[e.State]++
+		if e.CreatedAt.IsZero() || e.Uses != 1 {
+			t.Errorf("unexpected entry %+v", e)
+		}
+	}
+	if states["idle"] != 1 || states["active"] != 1 {
+		t.Errorf("states=%v, want 1 idle and 1 active", states)
+	}
+
+	lines := strings.Split(strings.TrimSpace(p.DumpString()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "STATE") {
+		t.Errorf("unexpected dump:\n%s", p.DumpString())
+	}
+	p.Put(o2)
+}
+
+func TestPoolIdleConnections(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.TrackActive = true // 记录借出对象时才会累计Uses
+	defer p.Close()
+
+	o1, _ := p.Get()
+	o2, _ := p.Get()
+	p.Put(o1)
+	p.Put(o2)
+	o2, _ = p.Get()
+	p.Put(o2)
+
+	infos := p.IdleConnections()
+	if len(infos) != 2 {
+		t.Fatalf("len=%d, want 2", len(infos))
+	}
+	if infos[0].Obj != o2 || infos[1].Obj != o1 {
+		t.Errorf("infos=%v", infos)
+	}
+	if infos[0].Uses != 2 || infos[1].Uses != 1 {
+		t.Errorf("uses=%d,%d, want 2,1", infos[0].Uses, infos[1].Uses)
+	}
+	if infos[1].CreatedAt.IsZero() || infos[1].IdleSince.Before(infos[1].CreatedAt) {
+		t.Errorf("unexpected times %v", infos[1])
+	}
+}
+
+func TestPoolInspect(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	defer p.Close()
+
+	o1, _ := p.Get()
+	o2, _ := p.Get()
+	p.Put(o1)
+	p.Put(o2)
+
+	var objs []interface{}
+	p.Inspect(func(obj interface{}) {
+		objs = append(objs, obj)
+	})
+	if len(objs) != 2 || objs[0] != o2 || objs[1] != o1 {
+		t.Errorf("Inspect() visited %v, want [%v %v]", objs, o2, o1)
+	}
+	if n := p.IdleCount(); n != 2 {
+		t.Errorf("IdleCount()=%d, want 2", n)
+	}
+}
+
+func TestPoolForEachIdle(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 3)
+	p.DropCallback = d.drop
+	defer p.Close()
+
+	var objs []interface{}
+	for i := 0; i < 3; i++ {
+		o, _ := p.Get()
+		objs = append(objs, o)
+	}
+	for _, o := range objs {
+		p.Put(o)
+	}
+
+	var visited []interface{}
+	n := p.ForEachIdle(func(obj interface{}, info ConnectionInfo) bool {
+		visited = append(visited, obj)
+		return obj.(*conn).id != 2
+	})
+	if n != 1 {
+		t.Errorf("ForEachIdle()=%d, want 1", n)
+	}
+	if len(visited) != 3 || visited[0] != objs[2] || visited[2] != objs[0] {
+		t.Errorf("visited %v, want newest first", visited)
+	}
+	d.check("for each idle", p, 3, 2)
+	if n := p.IdleCount(); n != 2 {
+		t.Errorf("IdleCount()=%d, want 2", n)
+	}
+}
+
+func TestPoolRange(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.TrackActive = true
+	defer p.Close()
+
+	o1, _ := p.Get()
+	o2, _ := p.Get()
+	p.Put(o1)
+
+	states := map[interface{}]string{}
+	p.Range(func(obj interface{}, state string) bool {
+		p.ActiveCount() // fn在锁外调用
+		states[obj] = state
+		return true
+	})
+	if len(states) != 2 || states[o1] != "idle" || states[o2] != "active" {
+		t.Errorf("Range() states=%v", states)
+	}
+
+	n := 0
+	p.Range(func(interface{}, string) bool {
+		n++
+		return false
+	})
+	if n != 1 {
+		t.Errorf("Range() called fn %d times after returning false, want 1", n)
+	}
+	p.Put(o2)
+}
+
+func TestPoolFilter(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 3)
+	p.DropCallback = d.drop
+	defer p.Close()
+
+	var objs []interface{}
+	for i := 0; i < 3; i++ {
+		o, _ := p.Get()
+		objs = append(objs, o)
+	}
+	for _, o := range objs {
+		p.Put(o)
+	}
+
+	var borrowed interface{}
+	n := p.Filter(func(obj interface{}) bool {
+		if borrowed == nil { // keep在锁外调用，可以Get，借出的对象不会被移除
+			borrowed, _ = p.Get()
+		}
+		return false
+	})
+	if n != 2 {
+		t.Errorf("Filter()=%d, want 2", n)
+	}
+	if borrowed != objs[2] {
+		t.Errorf("borrowed %v, want %v", borrowed, objs[2])
+	}
+	d.check("filter", p, 3, 1)
+	p.Put(borrowed)
+	if n := p.IdleCount(); n != 1 {
+		t.Errorf("IdleCount()=%d, want 1", n)
+	}
+}
+
+func TestPoolFilterUnhashable(t *testing.T) {
+	n := 0
+	p := NewPool(func() (interface{}, error) { n++; return []int{n}, nil }, 2)
+	defer p.Close()
+
+	o1, _ := p.Get()
+	o2, _ := p.Get()
+	p.Put(o1)
+	p.Put(o2)
+	removed := p.Filter(func(obj interface{}) bool { return obj.([]int)[0] == 1 })
+	if removed != 1 {
+		t.Errorf("Filter()=%d, want 1", removed)
+	}
+	if o, _ := p.Get(); o.([]int)[0] != 1 {
+		t.Errorf("Get()=%v, want [1]", o)
+	}
+}
+
+type wrappedConn struct {
+	inner interface{}
+}
+
+func TestPoolMap(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	defer p.Close()
+
+	o1, _ := p.Get()
+	o2, _ := p.Get()
+	p.Put(o1)
+	p.Map(func(obj interface{}) interface{} {
+		return &wrappedConn{obj}
+	})
+	if o, _ := p.Get(); o.(*wrappedConn).inner != o1 {
+		t.Errorf("Get()=%v, want wrapped %v", o, o1)
+	}
+	p.Put(o2) // 借出的对象不受影响
+	if o, _ := p.Get(); o != o2 {
+		t.Errorf("Get()=%v, want %v", o, o2)
+	}
+}
+
+func TestPoolWithLock(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 1)
+	o, _ := p.Get()
+
+	var closed bool
+	p.WithLock(func() {
+		closed = p.closed
+	})
+	if closed {
+		t.Error("pool closed before Close()")
+	}
+	p.Put(o)
+	p.Close()
+	p.WithLock(func() {
+		closed = p.closed
+	})
+	if !closed {
+		t.Error("pool not closed after Close()")
+	}
+}
@@ -0,0 +1,64 @@
+package pool
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// PoolStats 是pool的统计数据
+type PoolStats struct {
+	Hits      int64 `json:"hits"`      // 从空闲列表中获取到对象的次数
+	Misses    int64 `json:"misses"`    // 创建新对象的次数
+	Errors    int64 `json:"errors"`    // 创建对象失败的次数
+	Evictions int64 `json:"evictions"` // 空闲对象因为过期或超出MaxIdle等原因被移除的次数
+	Exhausted int64 `json:"exhausted"` // 对象数达到MaxActive的次数
+	Waits     int64 `json:"waits"`     // Get()需要等待的次数
+}
+
+type poolStats struct {
+	hits      atomic.Int64
+	misses    atomic.Int64
+	errors    atomic.Int64
+	evictions atomic.Int64
+	exhausted atomic.Int64
+	waits     atomic.Int64
+}
+
+// Stats 返回pool的统计数据
+func (p *Pool) Stats() PoolStats {
+	return PoolStats{
+		Hits:      p.stats.hits.Load(),
+		Misses:    p.stats.misses.Load(),
+		Errors:    p.stats.errors.Load(),
+		Evictions: p.stats.evictions.Load(),
+		Exhausted: p.stats.exhausted.Load(),
+		Waits:     p.stats.waits.Load(),
+	}
+}
+
+func (s *poolStats) reset() {
+	s.hits.Store(0)
+	s.misses.Store(0)
+	s.errors.Store(0)
+	s.evictions.Store(0)
+	s.exhausted.Store(0)
+	s.waits.Store(0)
+}
+
+// IdleAgeHistogram 按空闲时间统计空闲对象的数量，buckets需要从小到大排列。
+// 返回值的长度为len(buckets)+1，result[i]是空闲时间在[buckets[i-1], buckets[i])中的对象数
+func (p *Pool) IdleAgeHistogram(buckets []time.Duration) []int {
+	result := make([]int, len(buckets)+1)
+	p.mu.Lock()
+	now := nowFunc()
+	for e := p.idle.Front(); e != nil; e = e.Next() {
+		age := now.Sub(e.Value.IdleSince)
+		i := 0
+		for i < len(buckets) && age >= buckets[i] {
+			i++
+		}
+		result[i]++
+	}
+	p.mu.Unlock()
+	return result
+}
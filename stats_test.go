@@ -0,0 +1,39 @@
+package pool
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPoolIdleAgeHistogram(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 4)
+	defer p.Close()
+
+	now := time.Now()
+	nowFunc = func() time.Time {
+		return now
+	}
+	defer func() {
+		nowFunc = time.Now
+	}()
+
+	var objs []interface{}
+	for i := 0; i < 4; i++ {
+		o, _ := p.Get()
+		objs = append(objs, o)
+	}
+	p.Put(objs[0]) // 10分钟
+	now = now.Add(9 * time.Minute)
+	p.Put(objs[1]) // 1分钟
+	now = now.Add(50 * time.Second)
+	p.Put(objs[2]) // 10秒
+	p.Put(objs[3])
+	now = now.Add(10 * time.Second)
+
+	got := p.IdleAgeHistogram([]time.Duration{5 * time.Second, 30 * time.Second, 5 * time.Minute})
+	if fmt.Sprint(got) != "[0 2 1 1]" {
+		t.Errorf("histogram=%v, want [0 2 1 1]", got)
+	}
+}
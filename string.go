@@ -0,0 +1,103 @@
+package pool
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// String 返回pool当前状态的摘要，如Pool{active:3/10, idle:2/5, closed:false, waiting:0}
+func (p *Pool) String() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return fmt.Sprintf("Pool{active:%d/%d, idle:%d/%d, closed:%t, waiting:%d}",
+		p.active, p.MaxActive, p.idle.Len(), p.maxIdle(), p.closed, len(p.waiters))
+}
+
+// DebugString 在String()之后输出每个等待者开始等待时的goroutine和调用栈，用于排查死锁或者对象耗尽的原因。
+// 调用栈只在开启TrackActive时记录
+func (p *Pool) DebugString() string {
+	p.mu.Lock()
+	waiters := make([]waiter, len(p.waiters))
+	for i, w := range p.waiters {
+		waiters[i] = waiter{WaiterInfo: w.WaiterInfo, stack: w.stack}
+	}
+	p.mu.Unlock()
+	sort.Slice(waiters, func(i, j int) bool { return waiters[i].ID < waiters[j].ID })
+
+	var b strings.Builder
+	b.WriteString(p.String())
+	now := nowFunc()
+	for _, w := range waiters {
+		fmt.Fprintf(&b, "\n\nwaiter %d (priority %d, waiting %v)", w.ID, w.Priority, now.Sub(w.WaitingSince))
+		if w.stack != nil {
+			fmt.Fprintf(&b, ":\ngoroutine %d:\n%s", w.GoroutineID, formatStack(w.stack))
+		}
+	}
+	return b.String()
+}
+
+// GoString 返回创建相同配置的Go表达式，只包含非零值的字段，函数和接口类型的字段会被忽略
+func (p *Pool) GoString() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var fields []string
+	v := reflect.ValueOf(p).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		f, fv := v.Type().Field(i), v.Field(i)
+		if !f.IsExported() || fv.IsZero() {
+			continue
+		}
+		if k := fv.Kind(); k == reflect.Func || k == reflect.Interface {
+			continue
+		}
+		fields = append(fields, fmt.Sprintf("%s: %#v", f.Name, fv.Interface()))
+	}
+	return "&pool.Pool{" + strings.Join(fields, ", ") + "}"
+}
+
+// ProgressBar 返回宽度为width的进度条，如[===..   ] 4/10 active, 1 idle。
+// =表示借出的对象，.表示空闲对象，空格表示未使用的容量；MaxActive为0时以当前的对象数作为容量。
+// 不为0的部分至少占一格，width小于0时按0处理
+func (p *Pool) ProgressBar(width int) string {
+	if width < 0 {
+		width = 0
+	}
+	p.mu.Lock()
+	active, idle, max := p.active, p.idle.Len(), p.MaxActive
+	p.mu.Unlock()
+
+	capacity := max
+	if capacity <= 0 {
+		capacity = active
+	}
+	borrowedCells, idleCells := 0, 0
+	if capacity > 0 && width > 0 {
+		borrowedCells = cells(active-idle, width, capacity)
+		idleCells = cells(idle, width, capacity)
+		if borrowedCells > width {
+			borrowedCells = width
+		}
+		if idleCells > width-borrowedCells {
+			idleCells = width - borrowedCells
+		}
+		if idleCells == 0 && idle > 0 && borrowedCells > 1 { // 借出的对象让出一格
+			borrowedCells--
+			idleCells = 1
+		}
+	}
+	bar := strings.Repeat("=", borrowedCells) + strings.Repeat(".", idleCells) + strings.Repeat(" ", width-borrowedCells-idleCells)
+	if max > 0 {
+		return fmt.Sprintf("[%s] %d/%d active, %d idle", bar, active, max, idle)
+	}
+	return fmt.Sprintf("[%s] %d active, %d idle", bar, active, idle)
+}
+
+// cells 返回n个对象在宽度为width、容量为capacity的进度条中占的格数，不为0时向上取整
+func cells(n, width, capacity int) int {
+	if n <= 0 {
+		return 0
+	}
+	return (n*width + capacity - 1) / capacity
+}
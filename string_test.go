@@ -0,0 +1,91 @@
+package pool
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPoolString(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 5)
+	p.MaxActive = 10
+	p.IdleTimeout = time.Second
+	p.Wait = true
+	p.Name = "db"
+
+	o, _ := p.Get()
+	p.Get()
+	p.Put(o)
+	if s, want := fmt.Sprint(p), "Pool{active:2/10, idle:1/5, closed:false, waiting:0}"; s != want {
+		t.Errorf("String()=%q, want %q", s, want)
+	}
+	if s, want := fmt.Sprintf("%#v", p), `&pool.Pool{Name: "db", MaxIdle: 5, MaxActive: 10, IdleTimeout: 1000000000, Wait: true}`; s != want {
+		t.Errorf("GoString()=%q, want %q", s, want)
+	}
+}
+
+func TestPoolProgressBar(t *testing.T) {
+	tests := []struct {
+		maxActive, borrowed, idle, width int
+		want                             string
+	}{
+		{10, 4, 3, 10, "[====...   ] 7/10 active, 3 idle"},
+		{10, 0, 0, 5, "[     ] 0/10 active, 0 idle"},
+		{4, 4, 0, 8, "[========] 4/4 active, 0 idle"},
+		{4, 1, 1, 2, "[=.] 2/4 active, 1 idle"},
+		{10, 1, 0, 4, "[=   ] 1/10 active, 0 idle"},
+		{10, 1, 0, -1, "[] 1/10 active, 0 idle"},
+		{0, 2, 2, 4, "[==..] 4 active, 2 idle"},
+	}
+	for _, tt := range tests {
+		d := &poolDialer{t: t}
+		p := NewPool(d.dial, tt.idle)
+		p.MaxActive = tt.maxActive
+		var objs []interface{}
+		for i := 0; i < tt.borrowed+tt.idle; i++ {
+			o, _ := p.Get()
+			objs = append(objs, o)
+		}
+		for _, o := range objs[:tt.idle] {
+			p.Put(o)
+		}
+		if s := p.ProgressBar(tt.width); s != tt.want {
+			t.Errorf("ProgressBar(%d)=%q, want %q", tt.width, s, tt.want)
+		}
+		p.Close()
+	}
+}
+
+func TestPoolDebugString(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 1)
+	p.MaxActive = 1
+	p.Wait = true
+	p.TrackActive = true
+	defer p.Close()
+
+	o, _ := p.Get()
+	done := make(chan struct{})
+	go func() {
+		o, _ := p.Get()
+		p.Put(o)
+		close(done)
+	}()
+	waitWaiters(t, p, 1)
+
+	s := p.DebugString()
+	if !strings.HasPrefix(s, p.String()) {
+		t.Errorf("DebugString()=%q, want prefix %q", s, p.String())
+	}
+	lines := strings.Split(s, "\n")
+	if len(lines) < 5 || !strings.HasPrefix(lines[2], "waiter 1 ") || !strings.HasPrefix(lines[3], "goroutine ") {
+		t.Fatalf("DebugString()=%q", s)
+	}
+	if !strings.Contains(lines[4], "TestPoolDebugString") {
+		t.Errorf("stack starts with %q, want the caller of Get()", lines[4])
+	}
+	p.Put(o)
+	<-done
+}
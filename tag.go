@@ -0,0 +1,24 @@
+package pool
+
+import (
+	"context"
+	"reflect"
+)
+
+// GetTagged 优先返回标签与tag相同（reflect.DeepEqual）的空闲对象，没有时与Get()相同。需要设置Tag
+func (p *Pool) GetTagged(tag interface{}) (interface{}, error) {
+	prefer := func(io ConnectionInfo) bool {
+		return reflect.DeepEqual(io.tag, tag)
+	}
+	return p.get(context.Background(), getOptions{prefer: prefer})
+}
+
+// findIdle 返回最新的满足prefer的空闲对象，调用时需持有锁
+func (p *Pool) findIdle(prefer func(ConnectionInfo) bool) *idleElem {
+	for e := p.idle.Front(); e != nil; e = e.Next() {
+		if prefer(e.Value) {
+			return e
+		}
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package pool
+
+import "testing"
+
+func TestPoolGetTagged(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 3)
+	p.DropCallback = d.drop
+	p.Tag = func(obj interface{}) interface{} {
+		return obj.(*conn).id % 2
+	}
+	defer p.Close()
+
+	var objs []interface{}
+	for i := 0; i < 3; i++ {
+		o, _ := p.Get()
+		objs = append(objs, o)
+	}
+	for _, o := range objs {
+		p.Put(o)
+	}
+
+	o, err := p.GetTagged(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o != objs[1] {
+		t.Errorf("GetTagged(0)=%v, want %v", o, objs[1])
+	}
+	if o2, _ := p.GetTagged(0); o2 != objs[2] { // 没有标签相同的对象，返回最新的
+		t.Errorf("GetTagged(0)=%v, want %v", o2, objs[2])
+	}
+	d.check("get tagged", p, 3, 3)
+}
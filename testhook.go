@@ -0,0 +1,41 @@
+package pool
+
+import (
+	"context"
+	"time"
+)
+
+// TestHook 用于在测试中控制创建对象的行为
+type TestHook struct {
+	ErrorOnDialN func(n int) error // 第n次（从1开始）创建对象前调用，返回错误时不调用New，直接返回该错误
+	DelayDial    time.Duration     // 每次创建对象前等待的时间，ctx被取消时返回ctx.Err()
+}
+
+// SetTestHook 设置TestHook，之后创建对象的次数从1开始重新计算
+func (p *Pool) SetTestHook(h TestHook) {
+	p.mu.Lock()
+	p.testHook = &h
+	p.dials = 0
+	p.mu.Unlock()
+}
+
+func (h *TestHook) wrap(dial func(context.Context) (interface{}, error), n int) func(context.Context) (interface{}, error) {
+	errorOnDialN, delay := h.ErrorOnDialN, h.DelayDial
+	return func(ctx context.Context) (interface{}, error) {
+		if delay > 0 {
+			t := time.NewTimer(delay)
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				t.Stop()
+				return nil, ctx.Err()
+			}
+		}
+		if errorOnDialN != nil {
+			if err := errorOnDialN(n); err != nil {
+				return nil, err
+			}
+		}
+		return dial(ctx)
+	}
+}
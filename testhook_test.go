@@ -0,0 +1,43 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPoolTestHook(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+	defer p.Close()
+
+	errDial := errors.New("dial failed")
+	p.SetTestHook(TestHook{ErrorOnDialN: func(n int) error {
+		if n == 2 {
+			return errDial
+		}
+		return nil
+	}})
+
+	o, err := p.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Get(); err != errDial {
+		t.Errorf("err=%v, want %v", err, errDial)
+	}
+	d.check("after error", p, 1, 1)
+	p.Put(o)
+
+	p.SetTestHook(TestHook{DelayDial: time.Second})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	o, _ = p.Get() // 空闲对象不经过TestHook
+	if _, err := p.GetContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("err=%v, want %v", err, context.DeadlineExceeded)
+	}
+	d.check("after delay", p, 1, 1)
+	p.Put(o)
+}
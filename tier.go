@@ -0,0 +1,14 @@
+package pool
+
+// maxTier 是Tier返回的等级数
+const maxTier = 4
+
+func clampTier(tier int) int {
+	if tier < 0 {
+		return 0
+	}
+	if tier >= maxTier {
+		return maxTier - 1
+	}
+	return tier
+}
@@ -0,0 +1,81 @@
+package pool
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPoolTier(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 4)
+	secondary := make(map[interface{}]bool)
+	p.Tier = func(obj interface{}) int {
+		if secondary[obj] {
+			return 1
+		}
+		return 0
+	}
+	defer p.Close()
+
+	var objs []interface{}
+	for i := 0; i < 3; i++ {
+		o, _ := p.Get()
+		objs = append(objs, o)
+	}
+	secondary[objs[2]] = true
+	for _, o := range objs {
+		p.Put(o)
+	}
+
+	// objs[2]是最新放回的，但等级更低
+	if o, _ := p.Get(); o != objs[1] {
+		t.Errorf("Get()=%v, want %v", o, objs[1])
+	}
+	if o, _ := p.Get(); o != objs[0] {
+		t.Errorf("Get()=%v, want %v", o, objs[0])
+	}
+	if o, _ := p.Get(); o != objs[2] { // 没有primary时借出secondary
+		t.Errorf("Get()=%v, want %v", o, objs[2])
+	}
+	for _, o := range objs {
+		p.Put(o)
+	}
+	d.check("tier", p, 3, 3)
+}
+
+func TestIdleListTiers(t *testing.T) {
+	var l idleList
+	l.PushFront(ConnectionInfo{Obj: 1, tier: 1})
+	l.PushFront(ConnectionInfo{Obj: 2, tier: 0})
+	l.PushBack(ConnectionInfo{Obj: 3, tier: 3})
+	l.PushFront(ConnectionInfo{Obj: 4, tier: 1})
+	l.PushBack(ConnectionInfo{Obj: 5, tier: 0})
+
+	var got []interface{}
+	for e := l.Front(); e != nil; e = e.Next() {
+		got = append(got, e.Value.Obj)
+	}
+	want := []interface{}{2, 5, 4, 1, 3}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("forward %v, want %v", got, want)
+	}
+	got = got[:0]
+	for e := l.Back(); e != nil; e = e.Prev() {
+		got = append(got, e.Value.Obj)
+	}
+	want = []interface{}{3, 1, 4, 5, 2}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("backward %v, want %v", got, want)
+	}
+	if e := l.TopBack(); e.Value.Obj != 5 {
+		t.Errorf("TopBack()=%v, want 5", e.Value.Obj)
+	}
+	if e := l.TierBack(2); e != nil {
+		t.Errorf("TierBack(2)=%v, want nil", e.Value.Obj)
+	}
+	l.Remove(l.Front())
+	l.Remove(l.Front())
+	if e := l.Front(); e.Value.Obj != 4 || l.Len() != 3 {
+		t.Errorf("Front()=%v, Len()=%d, want 4, 3", e.Value.Obj, l.Len())
+	}
+}
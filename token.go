@@ -0,0 +1,30 @@
+package pool
+
+// Borrow 与Get相同，但同时返回一个token，之后用Return(token, err)放回对象，避免放回其他pool的对象或错误的值。
+// token从1开始递增，出错时为0
+func (p *Pool) Borrow() (token uint64, obj interface{}, err error) {
+	obj, err = p.Get()
+	if err != nil {
+		return 0, nil, err
+	}
+	p.mu.Lock()
+	if p.tokens == nil {
+		p.tokens = make(map[uint64]interface{})
+	}
+	p.lastToken++
+	token = p.lastToken
+	p.tokens[token] = obj
+	p.mu.Unlock()
+	return token, obj, nil
+}
+
+// Return 放回Borrow借出的对象，与PutErr(obj, err)相同。token不存在（如已经放回过）时不做任何事
+func (p *Pool) Return(token uint64, err error) {
+	p.mu.Lock()
+	obj, ok := p.tokens[token]
+	delete(p.tokens, token)
+	p.mu.Unlock()
+	if ok {
+		p.PutErr(obj, err)
+	}
+}
@@ -0,0 +1,31 @@
+package pool
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPoolBorrowReturn(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 2)
+	p.DropCallback = d.drop
+	defer p.Close()
+
+	t1, o1, err := p.Borrow()
+	if err != nil || t1 == 0 {
+		t.Fatalf("Borrow()=%d, %v, %v", t1, o1, err)
+	}
+	t2, o2, _ := p.Borrow()
+	if t2 == t1 || o2 == o1 {
+		t.Errorf("Borrow() returned the same token or object twice")
+	}
+
+	p.Return(t1, nil)
+	p.Return(t1, nil) // 已经放回过
+	p.Return(12345, nil)
+	if n := p.IdleCount(); n != 1 {
+		t.Errorf("IdleCount()=%d, want 1", n)
+	}
+	p.Return(t2, errors.New("broken"))
+	d.check("after return", p, 2, 1)
+}
@@ -0,0 +1,38 @@
+package pool
+
+import (
+	"context"
+	"time"
+)
+
+// GetTrace 是Get()过程中的回调，通过WithGetTrace放到ctx中，用于链路追踪等。回调不会在持有锁时调用
+type GetTrace struct {
+	WaitStart func() // 开始等待可用对象时调用，一次Get()中可能调用多次
+}
+
+type getTraceKey struct{}
+
+// WithGetTrace 返回带有t的ctx，使用这个ctx调用GetContext等方法时会调用t中的回调
+func WithGetTrace(ctx context.Context, t *GetTrace) context.Context {
+	return context.WithValue(ctx, getTraceKey{}, t)
+}
+
+// getTrace 返回ctx中的GetTrace，没有时返回nil
+func getTrace(ctx context.Context) *GetTrace {
+	t, _ := ctx.Value(getTraceKey{}).(*GetTrace)
+	return t
+}
+
+type poolTimeoutKey struct{}
+
+// WithPoolTimeout 返回带有超时时间d的ctx，使用这个ctx调用GetContext等方法时整个Get最多用时d（ctx的deadline更早时以它为准），
+// 超时返回context.DeadlineExceeded。用于中间件在不修改下游代码的情况下设置获取对象的超时
+func WithPoolTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, poolTimeoutKey{}, d)
+}
+
+// poolTimeout 返回WithPoolTimeout放到ctx中的超时时间，没有时返回0
+func poolTimeout(ctx context.Context) time.Duration {
+	d, _ := ctx.Value(poolTimeoutKey{}).(time.Duration)
+	return d
+}
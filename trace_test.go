@@ -0,0 +1,62 @@
+package pool
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPoolGetTrace(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 1)
+	p.MaxActive = 1
+	p.Wait = true
+	defer p.Close()
+
+	waits := make(chan struct{}, 1)
+	ctx := WithGetTrace(context.Background(), &GetTrace{
+		WaitStart: func() { waits <- struct{}{} },
+	})
+	o, _ := p.GetContext(ctx) // 不需要等待
+	select {
+	case <-waits:
+		t.Fatal("WaitStart called without waiting")
+	default:
+	}
+
+	got := make(chan interface{})
+	go func() {
+		o, _ := p.GetContext(ctx)
+		got <- o
+	}()
+	<-waits
+	p.Put(o)
+	if o2 := <-got; o2 != o {
+		t.Errorf("GetContext()=%v, want %v", o2, o)
+	}
+	p.Put(o)
+}
+
+func TestWithPoolTimeout(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 1)
+	p.MaxActive = 1
+	p.Wait = true
+	defer p.Close()
+
+	o, _ := p.Get()
+	ctx := WithPoolTimeout(context.Background(), 10*time.Millisecond)
+	start := time.Now()
+	if _, err := p.GetContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("GetContext()=%v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("elapsed %v, want about 10ms", elapsed)
+	}
+	p.Put(o)
+	if o, err := p.GetContext(ctx); err != nil { // 不需要等待时不受影响
+		t.Errorf("GetContext()=%v", err)
+	} else {
+		p.Put(o)
+	}
+}
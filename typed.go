@@ -0,0 +1,82 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// PoolOf 包装了Pool，Get()直接返回C，不需要再做类型断言
+type PoolOf[C any] struct {
+	*Pool
+}
+
+// NewTypedPool 创建一个PoolOf，C实现了io.Closer时，丢弃对象会调用它的Close()。
+// 可以通过opts设置其他字段，如WithMaxIdle(n)
+func NewTypedPool[C any](newFunc func() (C, error), opts ...Option) *PoolOf[C] {
+	p := &Pool{
+		New: func() (interface{}, error) {
+			return newFunc()
+		},
+		DropCallback: func(obj interface{}) {
+			if c, ok := obj.(io.Closer); ok {
+				c.Close()
+			}
+		},
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return &PoolOf[C]{Pool: p}
+}
+
+// Get 与Pool.Get相同，但返回C
+func (p *PoolOf[C]) Get() (C, error) {
+	return p.GetContext(context.Background())
+}
+
+// GetContext 与Pool.GetContext相同，但返回C。
+// 得到的对象不是C时（如C是接口而newFunc返回了nil）丢弃这个对象并返回错误
+func (p *PoolOf[C]) GetContext(ctx context.Context) (C, error) {
+	obj, err := p.Pool.GetContext(ctx)
+	if err != nil {
+		var zero C
+		return zero, err
+	}
+	c, err := cast[C](obj)
+	if err != nil {
+		p.Pool.PutErr(obj, err)
+	}
+	return c, err
+}
+
+// Put 与Pool.Put相同
+func (p *PoolOf[C]) Put(c C) {
+	p.Pool.Put(c)
+}
+
+// PutErr 与Pool.PutErr相同，err不为nil时丢弃c
+func (p *PoolOf[C]) PutErr(c C, err error) {
+	p.Pool.PutErr(c, err)
+}
+
+// Do 与Pool.Do相同，但fn的参数是C。得到的对象不是C时不调用fn，丢弃这个对象并返回错误
+func (p *PoolOf[C]) Do(fn func(C) error) error {
+	return p.Pool.Do(func(obj interface{}) error {
+		c, err := cast[C](obj)
+		if err != nil {
+			return err
+		}
+		return fn(c)
+	})
+}
+
+// cast 把obj转换为C，obj为nil或者不是C时返回错误
+func cast[C any](obj interface{}) (C, error) {
+	c, ok := obj.(C)
+	if !ok {
+		return c, fmt.Errorf("pool: got %T, want %v", obj, reflect.TypeOf((*C)(nil)).Elem())
+	}
+	return c, nil
+}
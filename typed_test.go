@@ -0,0 +1,72 @@
+package pool
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+type closeConn struct {
+	id     int
+	closed bool
+}
+
+func (c *closeConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestTypedPool(t *testing.T) {
+	dialed := 0
+	p := NewTypedPool(func() (*closeConn, error) {
+		dialed++
+		return &closeConn{id: dialed}, nil
+	}, WithMaxIdle(1))
+	defer p.Close()
+
+	c, err := p.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.id != 1 {
+		t.Errorf("id=%d, want 1", c.id)
+	}
+	p.Put(c)
+
+	err = p.Do(func(c2 *closeConn) error {
+		if c2 != c {
+			t.Errorf("Do() got %v, want %v", c2, c)
+		}
+		return errors.New("broken")
+	})
+	if err == nil {
+		t.Error("want error")
+	}
+	if !c.closed {
+		t.Error("conn not closed after error")
+	}
+	if n := p.ActiveCount(); n != 0 {
+		t.Errorf("ActiveCount()=%d, want 0", n)
+	}
+}
+
+func TestTypedPoolNilInterface(t *testing.T) {
+	p := NewTypedPool(func() (io.Closer, error) {
+		return nil, nil
+	})
+	defer p.Close()
+
+	if _, err := p.Get(); err == nil {
+		t.Error("Get() of a nil interface succeeded")
+	}
+	called := false
+	if err := p.Do(func(io.Closer) error {
+		called = true
+		return nil
+	}); err == nil || called {
+		t.Errorf("Do()=%v, called=%t, want error without calling fn", err, called)
+	}
+	if n := p.ActiveCount(); n != 0 {
+		t.Errorf("ActiveCount()=%d, want 0", n)
+	}
+}
@@ -0,0 +1,61 @@
+package pool
+
+// Validate 检查配置是否有效，返回的错误的Code为ErrCodeInvalidConfig
+func (p *Pool) Validate() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.hasNewFunc() {
+		return p.err(errNewFunc)
+	}
+	if p.NewWithEndpoint != nil && len(p.Endpoints) == 0 {
+		return p.err(errNoEndpoints)
+	}
+	var msg string
+	switch {
+	case p.MaxIdle < 0:
+		msg = "MaxIdle must not be negative"
+	case p.MaxActive < 0:
+		msg = "MaxActive must not be negative"
+	case p.MaxActive > 0 && p.MaxIdle > p.MaxActive:
+		msg = "MaxIdle must not be greater than MaxActive"
+	case p.MaxIdlePercent < 0 || p.MaxIdlePercent > 1:
+		msg = "MaxIdlePercent must be in [0, 1]"
+	case p.MinIdle < 0:
+		msg = "MinIdle must not be negative"
+	case p.MinIdle > p.maxIdle(): // 设置了MaxIdlePercent时按它计算的上限
+		msg = "MinIdle must not be greater than MaxIdle"
+	case p.MaxWaiters < 0:
+		msg = "MaxWaiters must not be negative"
+	case p.MaxOverflow < 0:
+		msg = "MaxOverflow must not be negative"
+	case p.DegradationThreshold < 0 || p.DegradationThreshold > 1 || p.RecoveryThreshold < 0 || p.RecoveryThreshold > 1:
+		msg = "DegradationThreshold and RecoveryThreshold must be in [0, 1]"
+	case p.DegradationFactor < 0 || p.DegradationFactor >= 1:
+		msg = "DegradationFactor must be in [0, 1)"
+	case p.ShareReadOnly && p.IsReadOnly == nil:
+		msg = "IsReadOnly must be set when ShareReadOnly is true"
+	case p.BatchConcurrency < 0:
+		msg = "BatchConcurrency must not be negative"
+	case p.IdleTimeout < 0 || p.IdleTimeoutJitter < 0 || p.MaxIdleTime < 0 || p.WaitTimeout < 0 || p.DialTimeout < 0 || p.GracefulWaitTime < 0:
+		msg = "timeouts must not be negative"
+	case p.SlowStartInitial < 0 || p.SlowStartStep < 0:
+		msg = "SlowStartInitial and SlowStartStep must not be negative"
+	case p.AutoScale && p.AutoScaleMax > 0 && p.AutoScaleMin > p.AutoScaleMax:
+		msg = "AutoScaleMin must not be greater than AutoScaleMax"
+	default:
+		return nil
+	}
+	return p.err(&PoolError{Code: ErrCodeInvalidConfig, Msg: "pool: " + msg})
+}
+
+// New 用dial和opts创建Pool，并检查配置是否有效
+func New(dial func() (interface{}, error), opts ...Option) (*Pool, error) {
+	p := &Pool{New: dial}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
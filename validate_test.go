@@ -0,0 +1,37 @@
+package pool
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPoolValidate(t *testing.T) {
+	d := &poolDialer{t: t}
+	tests := []struct {
+		opts  []Option
+		valid bool
+	}{
+		{[]Option{WithMaxIdle(2), WithMaxActive(4)}, true},
+		{[]Option{WithMaxIdle(5)}, true},
+		{[]Option{WithMaxIdle(5), WithMaxActive(1)}, false},
+		{[]Option{WithMaxIdle(-1)}, false},
+		{[]Option{WithIdleTimeout(-1)}, false},
+		{[]Option{func(p *Pool) { p.MaxIdlePercent = 1.5 }}, false},
+		{[]Option{WithMaxActive(10), func(p *Pool) { p.MaxIdlePercent, p.MinIdle = 0.5, 2 }}, true},
+		{[]Option{WithMaxActive(10), func(p *Pool) { p.MaxIdlePercent, p.MinIdle = 0.5, 6 }}, false},
+		{[]Option{WithMaxIdle(1), func(p *Pool) { p.MinIdle = 2 }}, false},
+	}
+	for i, tt := range tests {
+		p, err := New(d.dial, tt.opts...)
+		if tt.valid != (err == nil) {
+			t.Errorf("%d: err=%v, valid=%t", i, err, tt.valid)
+		}
+		if err != nil && (p != nil || !errors.Is(err, &PoolError{Code: ErrCodeInvalidConfig})) {
+			t.Errorf("%d: p=%v, err=%v", i, p, err)
+		}
+	}
+
+	if _, err := New(nil); !errors.Is(err, errNewFunc) {
+		t.Errorf("err=%v, want %v", err, errNewFunc)
+	}
+}
@@ -0,0 +1,186 @@
+package pool
+
+import (
+	"container/heap"
+	"context"
+	"sort"
+	"time"
+)
+
+// WaiterInfo 描述一个阻塞在Get()中的goroutine
+type WaiterInfo struct {
+	ID           uint64
+	WaitingSince time.Time
+	Priority     int
+	GoroutineID  int64 // 只在开启TrackActive时记录
+}
+
+type waiter struct {
+	WaiterInfo
+	ch       chan struct{} // 被唤醒时收到通知
+	index    int           // 在waitHeap中的位置，不在heap中时为-1
+	assigned bool          // 设置了FairGet时，是否被分配了一个空闲对象
+	started  bool          // 是否已经调用过GetTrace.WaitStart
+	stack    []uintptr     // 开始等待时的调用栈，只在开启TrackActive时记录
+}
+
+// waitHeap 按优先级排序等待者，优先级相同时先等待的在前
+type waitHeap []*waiter
+
+func (h waitHeap) Len() int { return len(h) }
+
+func (h waitHeap) Less(i, j int) bool {
+	if h[i].Priority != h[j].Priority {
+		return h[i].Priority < h[j].Priority
+	}
+	return h[i].ID < h[j].ID
+}
+
+func (h waitHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *waitHeap) Push(x interface{}) {
+	w := x.(*waiter)
+	w.index = len(*h)
+	*h = append(*h, w)
+}
+
+func (h *waitHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	w.index = -1
+	*h = old[:n-1]
+	return w
+}
+
+// GetWithPriority 与GetContext相同，但需要等待时，priority越小的越先被唤醒
+func (p *Pool) GetWithPriority(ctx context.Context, priority int) (interface{}, error) {
+	return p.get(ctx, getOptions{priority: priority})
+}
+
+// newWaiter 创建一个等待者，同一次Get()中被唤醒后需要再次等待时复用它，
+// 保持最初的ID和开始等待的时间，不会排到之后才开始等待的goroutine后面。调用时需持有锁
+func (p *Pool) newWaiter(priority int) *waiter {
+	p.stats.waits.Add(1)
+	p.waiterID++
+	w := &waiter{
+		WaiterInfo: WaiterInfo{ID: p.waiterID, WaitingSince: nowFunc(), Priority: priority},
+		ch:         make(chan struct{}, 1),
+		index:      -1,
+	}
+	if p.TrackActive {
+		w.GoroutineID = goroutineID()
+		w.stack = callers()
+	}
+	return w
+}
+
+// waitTurn 把w放入等待队列，阻塞直到被唤醒、wake被关闭或ctx被取消，调用时需持有锁，返回时也持有锁。
+// assigned表示被唤醒时分配到了一个空闲对象（见signalIdle），
+// 返回时分配已经解除，调用者在释放锁之前可以忽略其他等待者的分配取走一个空闲对象
+func (p *Pool) waitTurn(ctx context.Context, w *waiter, wake <-chan struct{}) (assigned bool, err error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	w.assigned = false
+	heap.Push(&p.waiters, w)
+	logs := p.takeLogs()
+	p.mu.Unlock()
+	emitLogs(logs)
+	if t := getTrace(ctx); t != nil && t.WaitStart != nil && !w.started {
+		t.WaitStart()
+	}
+	w.started = true
+
+	select {
+	case <-w.ch:
+		p.mu.Lock()
+		if w.assigned {
+			p.assigned--
+		}
+		return w.assigned, nil
+	case <-wake:
+		p.mu.Lock()
+		if w.index >= 0 {
+			heap.Remove(&p.waiters, w.index)
+		} else {
+			p.passOn(w)
+		}
+		return false, nil
+	case <-ctx.Done():
+		p.mu.Lock()
+		if w.index >= 0 {
+			heap.Remove(&p.waiters, w.index)
+		} else {
+			p.passOn(w) // 已经收到了通知，传给下一个等待者
+		}
+		return false, ctx.Err()
+	}
+}
+
+// passOn 把w收到的通知传给下一个等待者，调用时需持有锁
+func (p *Pool) passOn(w *waiter) {
+	<-w.ch // 通知已经在signal或signalIdle中发出，取出后w可以再次等待
+	if w.assigned {
+		p.assigned--
+		p.signalIdle()
+	} else {
+		p.signal()
+	}
+}
+
+// signal 唤醒优先级最高的等待者，调用时需持有锁
+func (p *Pool) signal() {
+	p.notifyIdle()
+	if len(p.waiters) > 0 {
+		w := heap.Pop(&p.waiters).(*waiter)
+		w.ch <- struct{}{}
+	}
+}
+
+// signalIdle 在有对象放回空闲列表时唤醒等待者。设置了FairGet时这个对象被分配给唤醒的等待者，
+// 在它取走之前其他goroutine不能借出，调用时需持有锁
+func (p *Pool) signalIdle() {
+	p.notifyIdle()
+	if !p.FairGet || len(p.waiters) == 0 {
+		p.signal()
+		return
+	}
+	w := heap.Pop(&p.waiters).(*waiter)
+	w.assigned = true
+	p.assigned++
+	w.ch <- struct{}{}
+}
+
+// broadcast 唤醒所有等待者，调用时需持有锁
+func (p *Pool) broadcast() {
+	p.notifyIdle()
+	for len(p.waiters) > 0 {
+		p.signal()
+	}
+}
+
+// WaiterCount 返回阻塞在Get()中等待对象的goroutine数
+func (p *Pool) WaiterCount() int {
+	p.mu.Lock()
+	n := len(p.waiters)
+	p.mu.Unlock()
+	return n
+}
+
+// WaitQueue 返回当前所有等待者的快照，按开始等待的先后排序
+func (p *Pool) WaitQueue() []WaiterInfo {
+	p.mu.Lock()
+	waiters := make([]WaiterInfo, len(p.waiters))
+	for i, w := range p.waiters {
+		waiters[i] = w.WaiterInfo
+	}
+	p.mu.Unlock()
+	sort.Slice(waiters, func(i, j int) bool { return waiters[i].ID < waiters[j].ID })
+	return waiters
+}
@@ -0,0 +1,211 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func waitWaiters(t *testing.T, p *Pool, want int) {
+	deadline := time.Now().Add(2 * time.Second)
+	for p.WaiterCount() != want && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := p.WaiterCount(); n != want {
+		t.Fatalf("waiters=%d, want %d", n, want)
+	}
+}
+
+func TestWaitPoolPriority(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := &Pool{
+		New:       d.dial,
+		MaxIdle:   1,
+		MaxActive: 1,
+		Wait:      true,
+	}
+	defer p.Close()
+
+	o, _ := p.Get()
+	order := make(chan int, 3)
+	for i, priority := range []int{3, 1, 2} {
+		go func(priority int) {
+			o, err := p.GetWithPriority(context.Background(), priority)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			order <- priority
+			p.Put(o)
+		}(priority)
+		waitWaiters(t, p, i+1)
+	}
+
+	queue := p.WaitQueue()
+	if got := fmt.Sprint(queue[0].Priority, queue[1].Priority, queue[2].Priority); got != "3 1 2" {
+		t.Errorf("wait queue priorities=%s, want 3 1 2", got)
+	}
+
+	p.Put(o)
+	var got []int
+	for i := 0; i < 3; i++ {
+		got = append(got, <-order)
+	}
+	if fmt.Sprint(got) != "[1 2 3]" {
+		t.Errorf("order=%v, want [1 2 3]", got)
+	}
+}
+
+func TestPoolWaiterKeepsPlace(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := &Pool{
+		New:       d.dial,
+		MaxIdle:   1,
+		MaxActive: 1,
+		Wait:      true,
+	}
+	defer p.Close()
+
+	o, _ := p.Get()
+	got := make(chan int, 2)
+	for i := 0; i < 2; i++ {
+		go func(i int) {
+			o, _ := p.Get()
+			got <- i
+			p.Put(o)
+		}(i)
+		waitWaiters(t, p, i+1)
+	}
+	first := p.WaitQueue()[0]
+
+	p.mu.Lock()
+	p.signal() // 唤醒第一个等待者，但没有可用的对象，它需要再次等待
+	p.mu.Unlock()
+	waitWaiters(t, p, 2)
+	if w := p.WaitQueue()[0]; w.ID != first.ID || !w.WaitingSince.Equal(first.WaitingSince) {
+		t.Errorf("first waiter after wakeup=%+v, want %+v", w, first)
+	}
+
+	p.Put(o)
+	if i := <-got; i != 0 {
+		t.Errorf("waiter %d got the object first, want 0", i)
+	}
+	<-got
+}
+
+func TestPoolWaitCancelPassesOn(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := &Pool{
+		New:       d.dial,
+		MaxIdle:   1,
+		MaxActive: 1,
+		Wait:      true,
+	}
+	defer p.Close()
+
+	o, _ := p.Get()
+	for i := 0; i < 50; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		canceled := make(chan error, 1)
+		go func() {
+			o, err := p.GetWithPriority(ctx, 0)
+			if err == nil {
+				p.Put(o)
+			}
+			canceled <- err
+		}()
+		waitWaiters(t, p, 1)
+		got := make(chan interface{}, 1)
+		go func() {
+			o, _ := p.GetWithPriority(context.Background(), 1)
+			got <- o
+		}()
+		waitWaiters(t, p, 2)
+
+		go cancel() // 和Put同时发生，已经收到的通知要传给下一个等待者
+		p.Put(o)
+		<-canceled
+		select {
+		case o = <-got:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("iteration %d: second waiter was not woken", i)
+		}
+	}
+	p.Put(o)
+}
+
+func TestPoolFairGet(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 1)
+	p.MaxActive = 1
+	p.Wait = true
+	p.FairGet = true
+	defer p.Close()
+
+	o, _ := p.Get()
+	got := make(chan interface{})
+	go func() {
+		o, _ := p.Get()
+		got <- o
+	}()
+	waitWaiters(t, p, 1)
+
+	// 对象已经分配给了先等待的goroutine，即使有偏好也不能抢走
+	p.Put(o)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	prefer := func(ConnectionInfo) bool { return true }
+	if _, err := p.get(ctx, getOptions{prefer: prefer}); err != context.DeadlineExceeded {
+		t.Errorf("get(prefer)=%v, want DeadlineExceeded", err)
+	}
+	select {
+	case o2 := <-got:
+		if o2 != o {
+			t.Errorf("waiter got %v, want %v", o2, o)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiter did not get the returned object")
+	}
+	p.Put(o)
+	d.check("fair", p, 1, 1)
+}
+
+func TestPoolFairGetOrder(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 1)
+	p.MaxActive = 1
+	p.Wait = true
+	p.FairGet = true
+	defer p.Close()
+
+	o, _ := p.Get()
+	order := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		go func(i int) {
+			o, err := p.Get()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			order <- i
+			p.Put(o)
+		}(i)
+		waitWaiters(t, p, i+1)
+	}
+
+	p.Put(o)
+	var got []int
+	for i := 0; i < 3; i++ {
+		select {
+		case n := <-order:
+			got = append(got, n)
+		case <-time.After(time.Second):
+			t.Fatal("waiters did not get the returned object")
+		}
+	}
+	if fmt.Sprint(got) != "[0 1 2]" {
+		t.Errorf("order=%v, want [0 1 2]", got)
+	}
+	d.check("fair order", p, 1, 1)
+}
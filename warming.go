@@ -0,0 +1,139 @@
+package pool
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// WarmingStrategy 决定后台如何预热pool：Next返回现在要创建多少个对象，以及多久之后再调用Next，
+// 返回的时间不大于0时停止预热。current是当前空闲对象的数量，target是MinIdle
+type WarmingStrategy interface {
+	Next(current, target int) (int, time.Duration)
+}
+
+// LazyStrategy 不预热，只在Get()时创建对象，与不设置WarmingStrategy相同
+type LazyStrategy struct{}
+
+func (LazyStrategy) Next(current, target int) (int, time.Duration) {
+	return 0, 0
+}
+
+// EagerStrategy 启动时立即把空闲对象补充到MinIdle个
+type EagerStrategy struct{}
+
+func (EagerStrategy) Next(current, target int) (int, time.Duration) {
+	return target - current, 0
+}
+
+// GradualStrategy 每隔Interval最多创建Rate个对象，直到空闲对象达到MinIdle，之后继续按Interval检查
+type GradualStrategy struct {
+	Rate     int
+	Interval time.Duration
+}
+
+func (s GradualStrategy) Next(current, target int) (int, time.Duration) {
+	n := target - current
+	if n > s.Rate {
+		n = s.Rate
+	}
+	return n, s.Interval
+}
+
+// SetWarmingStrategy 设置预热策略，为nil时停止预热。pool已经开始使用时会立即开始预热，否则在第一次Get()时开始
+func (p *Pool) SetWarmingStrategy(s WarmingStrategy) {
+	p.mu.Lock()
+	if p.warmStop != nil { // 不比较策略本身，策略可能是不能比较的类型，或者与之前的相等
+		close(p.warmStop)
+		p.warmStop = nil
+	}
+	p.warming = s
+	if s != nil && p.started && !p.closed {
+		p.goWarming(s)
+	}
+	p.mu.Unlock()
+}
+
+// goWarming 启动按s预热的后台goroutine，再次调用SetWarmingStrategy或pool被关闭时退出，调用时需持有锁
+func (p *Pool) goWarming(s WarmingStrategy) {
+	stop := make(chan struct{})
+	p.warmStop = stop
+	done := p.done
+	var ctxDone <-chan struct{}
+	if p.ctx != nil {
+		ctxDone = p.ctx.Done()
+	}
+	p.bg.Add(1)
+	go func() {
+		defer p.bg.Done()
+		for {
+			p.mu.Lock()
+			select {
+			case <-stop:
+				p.mu.Unlock()
+				return
+			default:
+			}
+			if p.closed {
+				p.mu.Unlock()
+				return
+			}
+			current, target := p.idle.Len(), p.MinIdle
+			p.mu.Unlock()
+
+			n, next := s.Next(current, target)
+			if n > 0 {
+				p.Warmup(n) // 出错时下次再试，pool关闭后会在下面退出
+			}
+			if next <= 0 {
+				return
+			}
+			t := time.NewTimer(next)
+			select {
+			case <-stop:
+				t.Stop()
+				return
+			case <-done:
+				t.Stop()
+				return
+			case <-ctxDone:
+				t.Stop()
+				return
+			case <-t.C:
+			}
+		}
+	}()
+}
+
+// goRefill 在后台创建一个对象放入空闲列表，用于RefillOnDrop。goroutine由Close等待，pool关闭时取消创建，调用时需持有锁
+func (p *Pool) goRefill() {
+	done, parent := p.done, p.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	p.bg.Add(1)
+	go func() {
+		defer p.bg.Done()
+		ctx, cancel := context.WithCancel(parent)
+		defer cancel()
+		go func() {
+			select {
+			case <-done:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		p.WarmupStaggered(ctx, 1, 0) // 出错时等janitor或下次丢弃时再补充
+	}()
+}
+
+// checkClosed 对io.ReadWriteCloser写入0个字节，返回写入的错误，如连接已经被关闭时的net.ErrClosed、io.ErrClosedPipe。
+// 其他对象返回nil
+func checkClosed(obj interface{}) error {
+	rwc, ok := obj.(io.ReadWriteCloser)
+	if !ok {
+		return nil
+	}
+	_, err := rwc.Write(nil)
+	return err
+}
@@ -0,0 +1,188 @@
+package pool
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWarmingStrategy(t *testing.T) {
+	tests := []struct {
+		s        WarmingStrategy
+		current  int
+		n        int
+		interval time.Duration
+	}{
+		{LazyStrategy{}, 0, 0, 0},
+		{EagerStrategy{}, 1, 2, 0},
+		{GradualStrategy{Rate: 1, Interval: time.Second}, 0, 1, time.Second},
+		{GradualStrategy{Rate: 5, Interval: time.Second}, 1, 2, time.Second},
+		{GradualStrategy{Rate: 1, Interval: time.Second}, 3, 0, time.Second},
+	}
+	for _, tt := range tests {
+		if n, interval := tt.s.Next(tt.current, 3); n != tt.n || interval != tt.interval {
+			t.Errorf("%#v.Next(%d, 3)=%d, %v, want %d, %v", tt.s, tt.current, n, interval, tt.n, tt.interval)
+		}
+	}
+}
+
+func TestPoolSetWarmingStrategy(t *testing.T) {
+	for _, s := range []WarmingStrategy{EagerStrategy{}, GradualStrategy{Rate: 1, Interval: 5 * time.Millisecond}} {
+		var dialed atomic.Int32
+		p := NewPool(func() (interface{}, error) {
+			dialed.Add(1)
+			return &conn{}, nil
+		}, 5)
+		p.MinIdle = 3
+
+		o, _ := p.Get()
+		p.Put(o)
+		p.SetWarmingStrategy(s)
+		deadline := time.Now().Add(2 * time.Second)
+		for p.IdleCount() < 3 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(20 * time.Millisecond)
+		if n := p.IdleCount(); n != 3 {
+			t.Errorf("%#v: IdleCount()=%d, want 3", s, n)
+		}
+		if n := dialed.Load(); n != 3 {
+			t.Errorf("%#v: dialed=%d, want 3", s, n)
+		}
+		p.Close()
+	}
+}
+
+func TestPoolRefillOnDrop(t *testing.T) {
+	var dialed atomic.Int32
+	p := NewPool(func() (interface{}, error) {
+		dialed.Add(1)
+		return &conn{}, nil
+	}, 2)
+	p.MinIdle = 1
+	p.RefillOnDrop = true
+	defer p.Close()
+
+	o, _ := p.Get()
+	p.PutErr(o, errors.New("connection reset"))
+	deadline := time.Now().Add(2 * time.Second)
+	for p.IdleCount() != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := p.IdleCount(); n != 1 {
+		t.Errorf("IdleCount()=%d, want 1", n)
+	}
+	if n := dialed.Load(); n != 2 {
+		t.Errorf("dialed=%d, want 2", n)
+	}
+}
+
+// countingStrategy 包含slice，不能比较
+type countingStrategy struct {
+	calls    *atomic.Int32
+	interval []time.Duration
+}
+
+func (s countingStrategy) Next(current, target int) (int, time.Duration) {
+	s.calls.Add(1)
+	return 0, s.interval[0]
+}
+
+func TestPoolSetWarmingStrategyTwice(t *testing.T) {
+	p := NewPool(func() (interface{}, error) { return &conn{}, nil }, 1)
+	defer p.Close()
+	o, _ := p.Get()
+	p.Put(o)
+
+	var calls atomic.Int32
+	s := countingStrategy{&calls, []time.Duration{10 * time.Millisecond}}
+	p.SetWarmingStrategy(s)
+	p.SetWarmingStrategy(s) // 相等的策略也会替换之前的goroutine
+	time.Sleep(105 * time.Millisecond)
+	p.SetWarmingStrategy(nil)
+	if n := calls.Load(); n > 13 { // 只有一个goroutine在运行时约为11次
+		t.Errorf("Next called %d times, want about 11", n)
+	}
+}
+
+// rwConn 是用于测试的io.ReadWriteCloser，Close之后写入会返回io.ErrClosedPipe
+type rwConn struct {
+	closed atomic.Bool
+}
+
+func (c *rwConn) Read(b []byte) (int, error) { return 0, io.EOF }
+
+func (c *rwConn) Write(b []byte) (int, error) {
+	if c.closed.Load() {
+		return 0, io.ErrClosedPipe
+	}
+	return len(b), nil
+}
+
+func (c *rwConn) Close() error {
+	c.closed.Store(true)
+	return nil
+}
+
+func TestPoolRefillOnPutClosed(t *testing.T) {
+	var dialed atomic.Int32
+	p := NewPool(func() (interface{}, error) {
+		dialed.Add(1)
+		return &rwConn{}, nil
+	}, 2)
+	p.MinIdle = 1
+	p.RefillOnDrop = true
+	dropped := make(chan interface{}, 1)
+	p.DropCallback = func(obj interface{}) { dropped <- obj }
+	defer p.Close()
+
+	o, _ := p.Get()
+	o.(*rwConn).Close()
+	p.Put(o) // 已经关闭的连接被丢弃
+	if d := <-dropped; d != o {
+		t.Errorf("dropped %v, want %v", d, o)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for p.IdleCount() != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := dialed.Load(); n != 2 {
+		t.Errorf("dialed=%d, want 2", n)
+	}
+	if o2, _ := p.Get(); o2 == o {
+		t.Errorf("Get() returned the closed connection")
+	}
+}
+
+func TestPoolRefillCanceledOnClose(t *testing.T) {
+	var dialed atomic.Int32
+	unblock := make(chan struct{})
+	defer close(unblock)
+	p := NewPool(func() (interface{}, error) {
+		if dialed.Add(1) > 1 {
+			<-unblock
+		}
+		return &conn{}, nil
+	}, 2)
+	p.MinIdle = 1
+	p.RefillOnDrop = true
+
+	o, _ := p.Get()
+	p.PutErr(o, errors.New("connection reset"))
+	deadline := time.Now().Add(2 * time.Second)
+	for dialed.Load() != 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	closed := make(chan struct{})
+	go func() {
+		p.Close() // 等待补充的goroutine退出，不会一直阻塞在New中
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Close() blocked on the refill")
+	}
+}
@@ -0,0 +1,132 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Warmup 创建n个对象并放入空闲列表，达到MaxIdle、MaxActive或PoolGroup的限制时提前结束。
+// 设置了OnWarmup时，它返回错误的对象会被丢弃，并返回这个错误
+func (p *Pool) Warmup(n int) error {
+	return p.WarmupStaggered(context.Background(), n, 0)
+}
+
+// WarmupStaggered 与Warmup相同，但每创建一个对象后等待stagger再创建下一个，避免同时建立大量连接。
+// ctx被取消时停止并返回ctx.Err()，已经创建的对象会保留在空闲列表中
+func (p *Pool) WarmupStaggered(ctx context.Context, n int, stagger time.Duration) error {
+	for i := 0; i < n; i++ {
+		if i > 0 && stagger > 0 {
+			t := time.NewTimer(stagger)
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				t.Stop()
+				return ctx.Err()
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		p.mu.Lock()
+		if p.closed {
+			err := p.err(ErrPoolClosed)
+			p.mu.Unlock()
+			return err
+		}
+		if p.idle.Len() >= p.maxIdle() || (p.maxActive() > 0 && p.active >= p.maxActive()) {
+			p.mu.Unlock()
+			return nil
+		}
+		if delay := p.takeDialToken(); delay > 0 { // 按MaxDialRate等待
+			p.mu.Unlock()
+			t := time.NewTimer(delay)
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				t.Stop()
+				return ctx.Err()
+			}
+			i--
+			continue
+		}
+		if err := p.addIdle(ctx, false, p.OnWarmup); err != nil {
+			if errors.Is(err, ErrGroupFull) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// addIdle 创建一个对象放入空闲列表，用于Warmup等在后台补充空闲对象的地方。
+// 调用时需持有锁，调用者已经检查过MaxActive并取得了MaxDialRate的令牌，返回时不再持有锁。
+// 超出PoolGroup的限制时返回ErrGroupFull；prepare不为nil时在对象创建后调用（如OnWarmup），返回错误时丢弃对象并返回这个错误；
+// 创建期间pool被关闭时丢弃对象并返回ErrPoolClosed，空闲对象已经达到MaxIdle时丢弃对象并返回nil。
+// probe为true时对象计入probed，见probeExhausted
+func (p *Pool) addIdle(ctx context.Context, probe bool, prepare func(interface{}) error) error {
+	dial, endpoint, err := p.dialer()
+	if err != nil {
+		p.returnDialToken()
+		p.mu.Unlock()
+		return err
+	}
+	if ok, _ := p.group.acquire(); !ok {
+		p.returnDialToken()
+		err := p.err(ErrGroupFull)
+		p.mu.Unlock()
+		return err
+	}
+	tagFunc, version := p.Tag, p.ConnectionVersion
+	events, observer := p.sink(), p.observer
+	p.active++
+	if probe {
+		p.probed++
+	}
+	p.mu.Unlock()
+
+	obj, err := dial(ctx)
+	var tag interface{}
+	if err == nil && tagFunc != nil {
+		tag = tagFunc(obj)
+	}
+	publish(events, EventCreated, obj, err)
+	if err == nil && observer != nil {
+		observer.OnCreate(obj)
+	}
+	var prepareErr error
+	if err == nil && prepare != nil {
+		prepareErr = prepare(obj)
+	}
+
+	p.mu.Lock()
+	if err != nil || prepareErr != nil || p.closed || p.idle.Len() >= p.maxIdle() {
+		if probe && p.probed > 0 {
+			p.probed--
+		}
+		p.release()
+		if err != nil {
+			p.dialFailed(err)
+			p.mu.Unlock()
+			return err
+		}
+		closed := p.closed
+		p.dropObjs(obj)
+		if prepareErr != nil {
+			return prepareErr
+		}
+		if closed {
+			return p.err(ErrPoolClosed)
+		}
+		return nil
+	}
+	now := nowFunc()
+	io := ConnectionInfo{Obj: obj, IdleSince: now, CreatedAt: now, Endpoint: endpoint, tag: tag, version: version, jitter: p.idleJitter()}
+	p.setExpires(&io)
+	p.idle.PushFront(io)
+	p.signal()
+	p.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,100 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPoolWarmup(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 3)
+	p.DropCallback = d.drop
+
+	if err := p.Warmup(5); err != nil {
+		t.Fatal(err)
+	}
+	d.check("warmup", p, 3, 3)
+	if n := p.IdleCount(); n != 3 {
+		t.Errorf("IdleCount()=%d, want 3", n)
+	}
+
+	o, _ := p.Get()
+	d.check("get", p, 3, 3)
+	p.Put(o)
+	p.Close()
+	d.check("after close", p, 3, 0)
+}
+
+func TestPoolWarmupDialerError(t *testing.T) {
+	p := &Pool{MaxIdle: 1, MaxDialRate: 1}
+	defer p.Close()
+	now := time.Now()
+	nowFunc = func() time.Time { return now }
+	defer func() { nowFunc = time.Now }()
+
+	if err := p.Warmup(1); err == nil {
+		t.Fatal("Warmup() without New succeeded")
+	}
+	d := &poolDialer{t: t}
+	p.SetNew(d.dial)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := p.WarmupStaggered(ctx, 1, 0); err != nil { // 上次没用掉的令牌已经放回
+		t.Errorf("WarmupStaggered()=%v", err)
+	}
+}
+
+func TestPoolWarmupStaggered(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 10)
+	p.DropCallback = d.drop
+	defer p.Close()
+
+	start := time.Now()
+	if err := p.WarmupStaggered(context.Background(), 3, 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("elapsed %v, want >= 40ms", elapsed)
+	}
+	d.check("staggered", p, 3, 3)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if err := p.WarmupStaggered(ctx, 5, time.Second); err != context.DeadlineExceeded {
+		t.Errorf("err=%v, want %v", err, context.DeadlineExceeded)
+	}
+	d.check("canceled", p, 4, 4)
+}
+
+func TestPoolOnWarmup(t *testing.T) {
+	d := &poolDialer{t: t}
+	p := NewPool(d.dial, 3)
+	p.DropCallback = d.drop
+	warmed := 0
+	p.OnWarmup = func(o interface{}) error {
+		if warmed++; warmed == 3 {
+			return errors.New("auth failed")
+		}
+		return nil
+	}
+
+	if err := p.Warmup(3); err == nil || err.Error() != "auth failed" {
+		t.Errorf("Warmup()=%v, want auth failed", err)
+	}
+	d.check("warmup", p, 3, 2)
+
+	o1, _ := p.Get()
+	o2, _ := p.Get()
+	o3, _ := p.Get() // Get()创建的对象不调用OnWarmup
+	if warmed != 3 {
+		t.Errorf("OnWarmup called %d times, want 3", warmed)
+	}
+	p.Put(o1)
+	p.Put(o2)
+	p.Put(o3)
+	p.Close()
+	d.check("after close", p, 4, 0)
+}